@@ -8,15 +8,35 @@ import (
 )
 
 type Configs struct {
-	App   *AppConfig `json:"app" mapstructure:"app"`
-	DB    *DBConf    `json:"db" mapstructure:"db"`
-	Token *Token     `json:"token" mapstructure:"token"`
-	Video *Video     `json:"video" mapstructure:"video"`
+	App        *AppConfig  `json:"app" mapstructure:"app"`
+	DB         *DBConf     `json:"db" mapstructure:"db"`
+	Token      *Token      `json:"token" mapstructure:"token"`
+	Video      *Video      `json:"video" mapstructure:"video"`
+	Analytics  *Analytics  `json:"analytics" mapstructure:"analytics"`
+	Emotions   *Emotions   `json:"emotions" mapstructure:"emotions"`
+	Search     *Search     `json:"search" mapstructure:"search"`
+	Speech     *Speech     `json:"speech" mapstructure:"speech"`
+	Coverage   *Coverage   `json:"coverage" mapstructure:"coverage"`
+	Similarity *Similarity `json:"similarity" mapstructure:"similarity"`
+	Integrity  *Integrity  `json:"integrity" mapstructure:"integrity"`
+	Interview  *Interview  `json:"interview" mapstructure:"interview"`
+	Invitation *Invitation `json:"invitation" mapstructure:"invitation"`
+	SMTP       *SMTP       `json:"smtp" mapstructure:"smtp"`
+	Scheduler  *Scheduler  `json:"scheduler" mapstructure:"scheduler"`
+	Webhook    *Webhook    `json:"webhook" mapstructure:"webhook"`
+	Outbox     *Outbox     `json:"outbox" mapstructure:"outbox"`
+	Analysis   *Analysis   `json:"analysis" mapstructure:"analysis"`
+	Metrics    *Metrics    `json:"metrics" mapstructure:"metrics"`
+	Tracing    *Tracing    `json:"tracing" mapstructure:"tracing"`
 }
 
 type AppConfig struct {
 	TimeOut time.Duration `json:"timeout" mapstructure:"timeout"`
 	Port    int           `json:"port" mapstructure:"port"`
+	// RequestTimeout bounds every request but the progress streams;
+	// AnalysisTimeout replaces it on the routes calling the analyzer.
+	RequestTimeout  time.Duration `json:"request_timeout" mapstructure:"request_timeout" default:"30s"`
+	AnalysisTimeout time.Duration `json:"analysis_timeout" mapstructure:"analysis_timeout" default:"15m"`
 }
 
 type DBConf struct {
@@ -27,6 +47,9 @@ type DBConf struct {
 	DBName   string        `json:"dbname" mapstructure:"db_name"`
 	SSLMode  string        `json:"sslmode" mapstructure:"ssl_mode"`
 	TimeOut  time.Duration `json:"timeout" mapstructure:"timeout"`
+	// Retries is how many times a transaction that hit a serialization
+	// failure or a deadlock runs again.
+	Retries int `json:"retries" mapstructure:"retries" default:"3"`
 }
 
 type Token struct {
@@ -38,6 +61,157 @@ type Video struct {
 	Url  string `json:"url" mapstructure:"url"`
 }
 
+type Analytics struct {
+	HeatmapWindow time.Duration `json:"heatmap_window" mapstructure:"heatmap_window" default:"5s"`
+}
+
+type Emotions struct {
+	Taxonomy []EmotionCategory `json:"taxonomy" mapstructure:"taxonomy"`
+}
+
+type EmotionCategory struct {
+	Name    string   `json:"name" mapstructure:"name"`
+	Valence float64  `json:"valence" mapstructure:"valence"`
+	Arousal float64  `json:"arousal" mapstructure:"arousal"`
+	Labels  []string `json:"labels" mapstructure:"labels"`
+}
+
+type Search struct {
+	DefaultLanguage string   `json:"default_language" mapstructure:"default_language" default:"english"`
+	Languages       []string `json:"languages" mapstructure:"languages"`
+}
+
+type Speech struct {
+	LongPause time.Duration       `json:"long_pause" mapstructure:"long_pause" default:"2s"`
+	Fillers   map[string][]string `json:"fillers" mapstructure:"fillers"`
+}
+
+// Coverage mixes the key point coverage of an answer, scaled to the score
+// range of the analyzer contract, into its score by BlendWeight, from 0 for
+// the analyzer score alone to 1 for coverage alone.
+type Coverage struct {
+	BlendWeight float64 `json:"blend_weight" mapstructure:"blend_weight"`
+}
+
+type Similarity struct {
+	Threshold   float64 `json:"threshold" mapstructure:"threshold" default:"0.5"`
+	ShingleSize int     `json:"shingle_size" mapstructure:"shingle_size" default:"5"`
+}
+
+type Integrity struct {
+	Threshold float64            `json:"threshold" mapstructure:"threshold" default:"5"`
+	Weights   map[string]float64 `json:"weights" mapstructure:"weights"`
+}
+
+type Interview struct {
+	Duration          time.Duration `json:"duration" mapstructure:"duration" default:"1h"`
+	QuestionTimeLimit time.Duration `json:"question_time_limit" mapstructure:"question_time_limit" default:"3m"`
+	Grace             time.Duration `json:"grace" mapstructure:"grace" default:"30s"`
+	LinkBaseURL       string        `json:"link_base_url" mapstructure:"link_base_url"`
+}
+
+type Invitation struct {
+	TTL         time.Duration `json:"ttl" mapstructure:"ttl" default:"72h"`
+	LinkBaseURL string        `json:"link_base_url" mapstructure:"link_base_url"`
+}
+
+type SMTP struct {
+	Driver    string `json:"driver" mapstructure:"driver" default:"log"`
+	Host      string `json:"host" mapstructure:"host"`
+	Port      int    `json:"port" mapstructure:"port" default:"587"`
+	Username  string `json:"username" mapstructure:"username"`
+	Password  string `json:"password" mapstructure:"password"`
+	From      string `json:"from" mapstructure:"from"`
+	OutputDir string `json:"output_dir" mapstructure:"output_dir"`
+}
+
+// Scheduler runs every enabled job once per Interval. Jobs are enabled
+// unless set to false in Jobs.
+type Scheduler struct {
+	Enabled            bool            `json:"enabled" mapstructure:"enabled" default:"true"`
+	Interval           time.Duration   `json:"interval" mapstructure:"interval" default:"1m"`
+	Jobs               map[string]bool `json:"jobs" mapstructure:"jobs"`
+	InvitationReminder time.Duration   `json:"invitation_reminder" mapstructure:"invitation_reminder" default:"24h"`
+	InterviewReminder  time.Duration   `json:"interview_reminder" mapstructure:"interview_reminder" default:"10m"`
+}
+
+// Webhook retries a failed delivery after BackoffBase, doubling the delay
+// up to BackoffMax, and gives up after MaxAttempts.
+type Webhook struct {
+	Timeout     time.Duration `json:"timeout" mapstructure:"timeout" default:"10s"`
+	MaxAttempts int           `json:"max_attempts" mapstructure:"max_attempts" default:"8"`
+	BackoffBase time.Duration `json:"backoff_base" mapstructure:"backoff_base" default:"30s"`
+	BackoffMax  time.Duration `json:"backoff_max" mapstructure:"backoff_max" default:"6h"`
+	BatchSize   int           `json:"batch_size" mapstructure:"batch_size" default:"50"`
+}
+
+type Outbox struct {
+	PollInterval time.Duration `json:"poll_interval" mapstructure:"poll_interval" default:"2s"`
+	BatchSize    int           `json:"batch_size" mapstructure:"batch_size" default:"100"`
+	MaxAttempts  int           `json:"max_attempts" mapstructure:"max_attempts" default:"10"`
+}
+
+// Metrics are served for Prometheus at Path.
+type Metrics struct {
+	Path string `json:"path" mapstructure:"path" default:"/metrics"`
+}
+
+// Tracing exports spans with Exporter: "otlp" over HTTP to Endpoint,
+// "stdout" to print them, or none when empty. SampleRatio of the traces
+// started here are kept; traces of callers keep their sampling.
+type Tracing struct {
+	Exporter    string  `json:"exporter" mapstructure:"exporter"`
+	Endpoint    string  `json:"endpoint" mapstructure:"endpoint" default:"localhost:4318"`
+	Insecure    bool    `json:"insecure" mapstructure:"insecure"`
+	ServiceName string  `json:"service_name" mapstructure:"service_name" default:"sp-interview-main-service"`
+	SampleRatio float64 `json:"sample_ratio" mapstructure:"sample_ratio" default:"1"`
+}
+
+// Analysis sends every question to the analyzer on its own, at most
+// Concurrency at a time, when PerQuestion is set. An interview is analyzed
+// by one replica at a time, under an advisory lock that holds a connection
+// of the pool until the analysis ends, queued in the limiter or not.
+type Analysis struct {
+	PerQuestion bool              `json:"per_question" mapstructure:"per_question"`
+	Concurrency int               `json:"concurrency" mapstructure:"concurrency" default:"4"`
+	Limits      *AnalyzerLimits   `json:"limits" mapstructure:"limits"`
+	Contract    *AnalyzerContract `json:"contract" mapstructure:"contract"`
+	Fixtures    *AnalyzerFixtures `json:"fixtures" mapstructure:"fixtures"`
+}
+
+// AnalyzerFixtures saves the calls to the analyzer to fixture files in Dir
+// with Mode "record", or serves the saved ones instead of calling the
+// analyzer with Mode "replay".
+type AnalyzerFixtures struct {
+	Mode string `json:"mode" mapstructure:"mode"`
+	Dir  string `json:"dir" mapstructure:"dir" default:"testdata/analyzer"`
+}
+
+// AnalyzerContract is what a response of the analyzer must satisfy to be
+// accepted. RequiredFields names the fields of a question result that must
+// not be empty.
+type AnalyzerContract struct {
+	MinScore       int      `json:"min_score" mapstructure:"min_score"`
+	MaxScore       int      `json:"max_score" mapstructure:"max_score" default:"10"`
+	RequiredFields []string `json:"required_fields" mapstructure:"required_fields"`
+}
+
+// AnalyzerLimits bounds the calls to the analyzer in flight, globally and
+// per company. Calls over the limits wait in a queue ordered by the
+// priority of the company's plan; re-analysis gets ReanalysisPriority on
+// top of it. Every replica of the service enforces the limits on its own,
+// so they are set to the analyzer's capacity divided by the replicas.
+type AnalyzerLimits struct {
+	MaxInFlight        int            `json:"max_in_flight" mapstructure:"max_in_flight" default:"8"`
+	MaxPerCompany      int            `json:"max_per_company" mapstructure:"max_per_company" default:"2"`
+	MaxQueue           int            `json:"max_queue" mapstructure:"max_queue" default:"100"`
+	MaxCompanyQueue    int            `json:"max_company_queue" mapstructure:"max_company_queue" default:"20"`
+	QueueTimeout       time.Duration  `json:"queue_timeout" mapstructure:"queue_timeout" default:"2m"`
+	RetryAfter         time.Duration  `json:"retry_after" mapstructure:"retry_after" default:"30s"`
+	PlanPriorities     map[string]int `json:"plan_priorities" mapstructure:"plan_priorities"`
+	ReanalysisPriority int            `json:"reanalysis_priority" mapstructure:"reanalysis_priority" default:"5"`
+}
+
 func New() (*Configs, error) {
 	configFile := "config/config.yaml"
 	viper.SetConfigFile(configFile)
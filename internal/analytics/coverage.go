@@ -0,0 +1,53 @@
+package analytics
+
+import (
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+)
+
+// Coverage matches answer against the expected key points of a question.
+// A point is covered when any of its keywords or their synonyms occurs in
+// the answer. Coverage is the weighted share of covered points.
+func Coverage(answer string, points []*models.KeyPoint) *models.KeywordCoverage {
+	tokens := tokenize(answer)
+	res := &models.KeywordCoverage{
+		Covered: make([]models.KeyPointMatch, 0),
+		Missed:  make([]models.KeyPointMatch, 0),
+	}
+
+	var total, covered float64
+	for _, point := range points {
+		match := models.KeyPointMatch{PublicID: point.PublicID, Text: point.Text}
+		for _, keyword := range point.Keywords {
+			for _, term := range append([]string{keyword.Term}, keyword.Synonyms...) {
+				if containsPhrase(tokens, tokenize(term)) {
+					match.MatchedTerms = append(match.MatchedTerms, term)
+				}
+			}
+		}
+
+		weight := point.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		total += weight
+		if len(match.MatchedTerms) != 0 {
+			covered += weight
+			res.Covered = append(res.Covered, match)
+		} else {
+			res.Missed = append(res.Missed, match)
+		}
+	}
+	if total > 0 {
+		res.Coverage = covered / total
+	}
+	return res
+}
+
+func containsPhrase(tokens, phrase []string) bool {
+	for i := range tokens {
+		if hasPhraseAt(tokens, i, phrase) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+)
+
+// maxHeatmapBins bounds the heatmap of a timeline whose length is unknown.
+const maxHeatmapBins = 1000
+
+type transitionKey struct {
+	from string
+	to   string
+}
+
+// emotionAccumulator collects emotion timelines of one or more questions.
+// Heatmap bins are aligned to the start of each question.
+type emotionAccumulator struct {
+	window      float64
+	seconds     map[string]float64
+	transitions map[transitionKey]int
+	pairs       int
+	bins        []map[string]float64
+}
+
+func newEmotionAccumulator(window time.Duration) *emotionAccumulator {
+	return &emotionAccumulator{
+		window:      window.Seconds(),
+		seconds:     make(map[string]float64),
+		transitions: make(map[transitionKey]int),
+	}
+}
+
+// add adds the timeline of an answer lasting duration seconds; the heatmap
+// ends with the answer when duration is positive.
+func (a *emotionAccumulator) add(results []models.EmotionResult, duration float64) {
+	timeline := make([]models.EmotionResult, len(results))
+	copy(timeline, results)
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].ExactTime < timeline[j].ExactTime
+	})
+
+	for i, e := range timeline {
+		a.seconds[e.Emotion] += e.Duration
+		if i > 0 {
+			a.pairs++
+			if prev := timeline[i-1].Emotion; prev != e.Emotion {
+				a.transitions[transitionKey{from: prev, to: e.Emotion}]++
+			}
+		}
+		a.addToBins(e, duration)
+	}
+}
+
+func (a *emotionAccumulator) addToBins(e models.EmotionResult, duration float64) {
+	if a.window <= 0 || e.Duration <= 0 {
+		return
+	}
+	start, end := math.Max(e.ExactTime, 0), e.ExactTime+e.Duration
+	if duration > 0 {
+		end = math.Min(end, duration)
+	}
+	end = math.Min(end, maxHeatmapBins*a.window)
+	if end <= start {
+		return
+	}
+	last := int(math.Ceil(end/a.window)) - 1
+	for len(a.bins) <= last {
+		a.bins = append(a.bins, make(map[string]float64))
+	}
+	for i := int(start / a.window); i <= last; i++ {
+		binStart, binEnd := float64(i)*a.window, float64(i+1)*a.window
+		overlap := math.Min(end, binEnd) - math.Max(start, binStart)
+		if overlap > 0 {
+			a.bins[i][e.Emotion] += overlap
+		}
+	}
+}
+
+func (a *emotionAccumulator) result() *models.EmotionAnalytics {
+	res := &models.EmotionAnalytics{
+		TimeShare:   make(map[string]float64),
+		Transitions: make([]models.EmotionTransition, 0, len(a.transitions)),
+		Heatmap:     make([]models.EmotionBin, 0, len(a.bins)),
+	}
+
+	var total float64
+	for _, s := range a.seconds {
+		total += s
+	}
+	best := -1.0
+	for emotion, s := range a.seconds {
+		if total > 0 {
+			res.TimeShare[emotion] = s / total
+		}
+		if s > best || (s == best && emotion < res.Dominant) {
+			best = s
+			res.Dominant = emotion
+		}
+	}
+
+	transitions := 0
+	for k, count := range a.transitions {
+		transitions += count
+		res.Transitions = append(res.Transitions, models.EmotionTransition{From: k.from, To: k.to, Count: count})
+	}
+	sort.Slice(res.Transitions, func(i, j int) bool {
+		ti, tj := res.Transitions[i], res.Transitions[j]
+		if ti.Count != tj.Count {
+			return ti.Count > tj.Count
+		}
+		if ti.From != tj.From {
+			return ti.From < tj.From
+		}
+		return ti.To < tj.To
+	})
+	if a.pairs > 0 {
+		res.Volatility = float64(transitions) / float64(a.pairs)
+	}
+
+	for i, bin := range a.bins {
+		res.Heatmap = append(res.Heatmap, models.EmotionBin{
+			Start:    float64(i) * a.window,
+			End:      float64(i+1) * a.window,
+			Emotions: bin,
+		})
+	}
+	return res
+}
+
+// Emotions computes time share, dominant emotion, transitions, volatility
+// and a heatmap binned by window for a single emotion timeline of an answer
+// lasting duration seconds, or of unknown length when it is not positive.
+func Emotions(results []models.EmotionResult, duration float64, window time.Duration) *models.EmotionAnalytics {
+	acc := newEmotionAccumulator(window)
+	acc.add(results, duration)
+	return acc.result()
+}
+
+// Interview fills the analytics of every question in result and of the
+// interview as a whole.
+func Interview(result *models.Result, window time.Duration) {
+	acc := newEmotionAccumulator(window)
+	for i := range result.Questions {
+		q := &result.Questions[i]
+		q.Analytics = Emotions(q.EmotionResults, q.Duration, window)
+		acc.add(q.EmotionResults, q.Duration)
+	}
+	result.Analytics = acc.result()
+}
@@ -0,0 +1,163 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+)
+
+func bins(window float64, emotions ...map[string]float64) []models.EmotionBin {
+	result := make([]models.EmotionBin, 0, len(emotions))
+	for i, e := range emotions {
+		result = append(result, models.EmotionBin{Start: float64(i) * window, End: float64(i+1) * window, Emotions: e})
+	}
+	return result
+}
+
+func TestEmotions(t *testing.T) {
+	tests := []struct {
+		name       string
+		results    []models.EmotionResult
+		duration   float64
+		dominant   string
+		share      map[string]float64
+		volatility float64
+		heatmap    []models.EmotionBin
+	}{
+		{
+			name:    "empty",
+			share:   map[string]float64{},
+			heatmap: bins(1),
+		},
+		{
+			name: "negative start",
+			results: []models.EmotionResult{
+				{Emotion: "happy", ExactTime: -2, Duration: 4},
+			},
+			dominant: "happy",
+			share:    map[string]float64{"happy": 1},
+			heatmap:  bins(1, map[string]float64{"happy": 1}, map[string]float64{"happy": 1}),
+		},
+		{
+			name: "entirely before the answer",
+			results: []models.EmotionResult{
+				{Emotion: "happy", ExactTime: -3, Duration: 1},
+			},
+			dominant: "happy",
+			share:    map[string]float64{"happy": 1},
+			heatmap:  bins(1),
+		},
+		{
+			name: "overlapping windows",
+			results: []models.EmotionResult{
+				{Emotion: "sad", ExactTime: 1.5, Duration: 1},
+				{Emotion: "happy", ExactTime: 0.5, Duration: 1},
+			},
+			dominant:   "happy",
+			share:      map[string]float64{"happy": 0.5, "sad": 0.5},
+			volatility: 1,
+			heatmap: bins(1,
+				map[string]float64{"happy": 0.5},
+				map[string]float64{"happy": 0.5, "sad": 0.5},
+				map[string]float64{"sad": 0.5},
+			),
+		},
+		{
+			name: "past the answer",
+			results: []models.EmotionResult{
+				{Emotion: "neutral", ExactTime: 1, Duration: 10},
+			},
+			duration: 3,
+			dominant: "neutral",
+			share:    map[string]float64{"neutral": 1},
+			heatmap:  bins(1, map[string]float64{}, map[string]float64{"neutral": 1}, map[string]float64{"neutral": 1}),
+		},
+		{
+			name: "dominant tie goes to the first name",
+			results: []models.EmotionResult{
+				{Emotion: "surprise", ExactTime: 0, Duration: 1},
+				{Emotion: "angry", ExactTime: 1, Duration: 1},
+				{Emotion: "surprise", ExactTime: 2, Duration: 1},
+				{Emotion: "angry", ExactTime: 3, Duration: 1},
+			},
+			dominant:   "angry",
+			share:      map[string]float64{"angry": 0.5, "surprise": 0.5},
+			volatility: 1,
+			heatmap: bins(1,
+				map[string]float64{"surprise": 1},
+				map[string]float64{"angry": 1},
+				map[string]float64{"surprise": 1},
+				map[string]float64{"angry": 1},
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Emotions(tt.results, tt.duration, time.Second)
+			if got.Dominant != tt.dominant {
+				t.Errorf("dominant = %q, want %q", got.Dominant, tt.dominant)
+			}
+			if !reflect.DeepEqual(got.TimeShare, tt.share) {
+				t.Errorf("time share = %v, want %v", got.TimeShare, tt.share)
+			}
+			if got.Volatility != tt.volatility {
+				t.Errorf("volatility = %v, want %v", got.Volatility, tt.volatility)
+			}
+			if !reflect.DeepEqual(got.Heatmap, tt.heatmap) {
+				t.Errorf("heatmap = %v, want %v", got.Heatmap, tt.heatmap)
+			}
+		})
+	}
+}
+
+func TestEmotionsUnknownLength(t *testing.T) {
+	got := Emotions([]models.EmotionResult{{Emotion: "happy", ExactTime: 0, Duration: 1e9}}, 0, time.Second)
+	if len(got.Heatmap) != maxHeatmapBins {
+		t.Errorf("heatmap has %d bins, want %d", len(got.Heatmap), maxHeatmapBins)
+	}
+}
+
+func TestInterview(t *testing.T) {
+	result := &models.Result{Questions: []models.QuestionResult{
+		{
+			PublicID: "q1",
+			Duration: 2,
+			EmotionResults: []models.EmotionResult{
+				{Emotion: "happy", ExactTime: 0, Duration: 2},
+			},
+		},
+		{
+			PublicID: "q2",
+			Duration: 3,
+			EmotionResults: []models.EmotionResult{
+				{Emotion: "sad", ExactTime: 0, Duration: 1},
+				{Emotion: "happy", ExactTime: 1, Duration: 5},
+			},
+		},
+	}}
+	Interview(result, time.Second)
+
+	for i, want := range []string{"happy", "happy"} {
+		if a := result.Questions[i].Analytics; a == nil || a.Dominant != want {
+			t.Errorf("question %d analytics = %+v, want dominant %q", i, a, want)
+		}
+	}
+	// the bins of both questions start with the answer
+	want := bins(1,
+		map[string]float64{"happy": 1, "sad": 1},
+		map[string]float64{"happy": 2},
+		map[string]float64{"happy": 1},
+	)
+	if got := result.Analytics.Heatmap; !reflect.DeepEqual(got, want) {
+		t.Errorf("interview heatmap = %v, want %v", got, want)
+	}
+	if got := result.Questions[1].Analytics.Heatmap; len(got) != 3 {
+		t.Errorf("second question heatmap has %d bins, want 3", len(got))
+	}
+	// the end of one answer and the start of the next are not a transition
+	if got := result.Analytics.Volatility; got != 1 {
+		t.Errorf("interview volatility = %v, want 1", got)
+	}
+}
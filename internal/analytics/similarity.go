@@ -0,0 +1,70 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+)
+
+const maxFragments = 5
+
+// Similarity compares two answers by the Jaccard index of their word
+// shingles and returns the passages of a that also appear in b. Answers
+// shorter than one shingle are never similar.
+func Similarity(a, b string, shingleSize int) (float64, []string) {
+	tokensA, tokensB := tokenize(a), tokenize(b)
+	shinglesA, shinglesB := shingles(tokensA, shingleSize), shingles(tokensB, shingleSize)
+	if len(shinglesA) == 0 || len(shinglesB) == 0 {
+		return 0, nil
+	}
+
+	shared := 0
+	for s := range shinglesA {
+		if _, ok := shinglesB[s]; ok {
+			shared++
+		}
+	}
+	score := float64(shared) / float64(len(shinglesA)+len(shinglesB)-shared)
+	if shared == 0 {
+		return score, nil
+	}
+
+	// mark the words of a covered by a shared shingle and collect the runs
+	covered := make([]bool, len(tokensA))
+	for i := 0; i+shingleSize <= len(tokensA); i++ {
+		if _, ok := shinglesB[strings.Join(tokensA[i:i+shingleSize], " ")]; ok {
+			for j := i; j < i+shingleSize; j++ {
+				covered[j] = true
+			}
+		}
+	}
+	fragments := make([]string, 0)
+	for i := 0; i < len(tokensA); i++ {
+		if !covered[i] {
+			continue
+		}
+		j := i
+		for j < len(tokensA) && covered[j] {
+			j++
+		}
+		fragments = append(fragments, strings.Join(tokensA[i:j], " "))
+		i = j
+	}
+	sort.SliceStable(fragments, func(i, j int) bool {
+		return len(fragments[i]) > len(fragments[j])
+	})
+	if len(fragments) > maxFragments {
+		fragments = fragments[:maxFragments]
+	}
+	return score, fragments
+}
+
+func shingles(tokens []string, size int) map[string]struct{} {
+	set := make(map[string]struct{})
+	if size <= 0 {
+		return set
+	}
+	for i := 0; i+size <= len(tokens); i++ {
+		set[strings.Join(tokens[i:i+size], " ")] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,112 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+)
+
+// SpeechAnalyzer derives communication metrics from answer transcripts.
+type SpeechAnalyzer struct {
+	longPause float64
+	fillers   map[string][][]string
+}
+
+func NewSpeechAnalyzer(cfg *config.Speech) *SpeechAnalyzer {
+	a := &SpeechAnalyzer{
+		longPause: cfg.LongPause.Seconds(),
+		fillers:   make(map[string][][]string),
+	}
+	for language, fillers := range cfg.Fillers {
+		phrases := make([][]string, 0, len(fillers))
+		for _, filler := range fillers {
+			if phrase := tokenize(filler); len(phrase) != 0 {
+				phrases = append(phrases, phrase)
+			}
+		}
+		// longer phrases first, so "you know" is not counted as "you"
+		sort.SliceStable(phrases, func(i, j int) bool {
+			return len(phrases[i]) > len(phrases[j])
+		})
+		a.fillers[language] = phrases
+	}
+	return a
+}
+
+// Analyze computes the speech metrics of a single answer. Pauses are only
+// reported when the analyzer returned word timestamps.
+func (a *SpeechAnalyzer) Analyze(q *models.QuestionResult, language string) *models.SpeechMetrics {
+	tokens := tokenize(q.Answer)
+	metrics := &models.SpeechMetrics{
+		WordCount: len(tokens),
+		Duration:  answerDuration(q),
+	}
+	if metrics.Duration > 0 {
+		metrics.WordsPerMinute = float64(len(tokens)) / (metrics.Duration / 60)
+	}
+
+	fillers := a.fillers[language]
+	for i := 0; i < len(tokens); i++ {
+		for _, phrase := range fillers {
+			if hasPhraseAt(tokens, i, phrase) {
+				metrics.FillerCount++
+				i += len(phrase) - 1
+				break
+			}
+		}
+	}
+
+	if len(tokens) != 0 {
+		distinct := make(map[string]struct{}, len(tokens))
+		for _, t := range tokens {
+			distinct[t] = struct{}{}
+		}
+		metrics.FillerRate = float64(metrics.FillerCount) / float64(len(tokens))
+		metrics.VocabularyRichness = float64(len(distinct)) / float64(len(tokens))
+	}
+
+	metrics.LongPauses = a.longPauses(q.Words)
+	return metrics
+}
+
+func (a *SpeechAnalyzer) longPauses(words []models.WordTimestamp) []models.Pause {
+	if a.longPause <= 0 || len(words) < 2 {
+		return nil
+	}
+	timeline := make([]models.WordTimestamp, len(words))
+	copy(timeline, words)
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Start < timeline[j].Start
+	})
+
+	pauses := make([]models.Pause, 0)
+	end := timeline[0].End
+	for _, w := range timeline[1:] {
+		if gap := w.Start - end; gap >= a.longPause {
+			pauses = append(pauses, models.Pause{Start: end, Duration: gap})
+		}
+		end = math.Max(end, w.End)
+	}
+	return pauses
+}
+
+// answerDuration prefers the video duration reported by the analyzer and
+// falls back to the end of the last word or emotion segment.
+func answerDuration(q *models.QuestionResult) float64 {
+	if q.Duration > 0 {
+		return q.Duration
+	}
+	var end float64
+	for _, w := range q.Words {
+		end = math.Max(end, w.End)
+	}
+	if end > 0 {
+		return end
+	}
+	for _, e := range q.EmotionResults {
+		end = math.Max(end, e.ExactTime+e.Duration)
+	}
+	return end
+}
@@ -0,0 +1,78 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+)
+
+// Taxonomy maps raw analyzer labels to the canonical emotion set.
+type Taxonomy struct {
+	byLabel map[string]config.EmotionCategory
+}
+
+func NewTaxonomy(cfg *config.Emotions) *Taxonomy {
+	t := &Taxonomy{byLabel: make(map[string]config.EmotionCategory)}
+	for _, category := range cfg.Taxonomy {
+		t.byLabel[normalizeLabel(category.Name)] = category
+		for _, label := range category.Labels {
+			t.byLabel[normalizeLabel(label)] = category
+		}
+	}
+	return t
+}
+
+// Normalize rewrites every emotion of result, those of the timelines and
+// the one of each question, to its canonical name and keeps the analyzer
+// label in RawEmotion. Labels missing from the taxonomy are stored as
+// models.EmotionUnknown and returned.
+func (t *Taxonomy) Normalize(result *models.Result) []string {
+	unknown := make(map[string]struct{})
+	for i := range result.Questions {
+		q := &result.Questions[i]
+		if q.RawEmotion == "" {
+			q.RawEmotion = q.Emotion
+		}
+		if q.RawEmotion != "" {
+			category, ok := t.byLabel[normalizeLabel(q.RawEmotion)]
+			if ok {
+				q.Emotion = category.Name
+			} else {
+				unknown[q.RawEmotion] = struct{}{}
+				q.Emotion = models.EmotionUnknown
+			}
+		}
+
+		emotions := q.EmotionResults
+		for j := range emotions {
+			e := &emotions[j]
+			if e.RawEmotion == "" {
+				e.RawEmotion = e.Emotion
+			}
+			category, ok := t.byLabel[normalizeLabel(e.RawEmotion)]
+			if !ok {
+				unknown[e.RawEmotion] = struct{}{}
+				e.Emotion = models.EmotionUnknown
+				e.Valence, e.Arousal = 0, 0
+				continue
+			}
+			e.Emotion = category.Name
+			e.Valence = category.Valence
+			e.Arousal = category.Arousal
+		}
+	}
+
+	labels := make([]string, 0, len(unknown))
+	for label := range unknown {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	result.UnknownEmotions = labels
+	return labels
+}
+
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
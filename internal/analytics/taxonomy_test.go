@@ -0,0 +1,98 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+)
+
+func testTaxonomy() *Taxonomy {
+	return NewTaxonomy(&config.Emotions{Taxonomy: []config.EmotionCategory{
+		{Name: "joy", Valence: 0.8, Arousal: 0.5, Labels: []string{"happiness", "Happy"}},
+		{Name: "sadness", Valence: -0.7, Arousal: -0.4, Labels: []string{"sad"}},
+	}})
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		question string
+		timeline []string
+		emotion  string
+		emotions []string
+		unknown  []string
+	}{
+		{
+			name:     "case and whitespace",
+			question: " HAPPY ",
+			timeline: []string{"Sad", "  happiness", "JOY"},
+			emotion:  "joy",
+			emotions: []string{"sadness", "joy", "joy"},
+			unknown:  []string{},
+		},
+		{
+			name:     "unknown labels",
+			question: "bored",
+			timeline: []string{"happy", "contempt", "bored"},
+			emotion:  models.EmotionUnknown,
+			emotions: []string{"joy", models.EmotionUnknown, models.EmotionUnknown},
+			unknown:  []string{"bored", "contempt"},
+		},
+		{
+			name:     "no question emotion",
+			timeline: []string{"sad"},
+			emotions: []string{"sadness"},
+			unknown:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeline := make([]models.EmotionResult, 0, len(tt.timeline))
+			for _, label := range tt.timeline {
+				timeline = append(timeline, models.EmotionResult{Emotion: label})
+			}
+			result := &models.Result{Questions: []models.QuestionResult{{Emotion: tt.question, EmotionResults: timeline}}}
+
+			unknown := testTaxonomy().Normalize(result)
+			if !reflect.DeepEqual(unknown, tt.unknown) || !reflect.DeepEqual(result.UnknownEmotions, tt.unknown) {
+				t.Errorf("unknown = %v and stored %v, want %v", unknown, result.UnknownEmotions, tt.unknown)
+			}
+			q := result.Questions[0]
+			if q.Emotion != tt.emotion || q.RawEmotion != tt.question {
+				t.Errorf("question emotion = %q from %q, want %q from %q", q.Emotion, q.RawEmotion, tt.emotion, tt.question)
+			}
+			for i, e := range q.EmotionResults {
+				if e.Emotion != tt.emotions[i] || e.RawEmotion != tt.timeline[i] {
+					t.Errorf("emotion %d = %q from %q, want %q from %q", i, e.Emotion, e.RawEmotion, tt.emotions[i], tt.timeline[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeTwice(t *testing.T) {
+	result := &models.Result{Questions: []models.QuestionResult{{
+		Emotion:        "Happy",
+		EmotionResults: []models.EmotionResult{{Emotion: "sad"}, {Emotion: "bored"}},
+	}}}
+	taxonomy := testTaxonomy()
+	taxonomy.Normalize(result)
+	unknown := taxonomy.Normalize(result)
+
+	q := result.Questions[0]
+	if q.Emotion != "joy" || q.RawEmotion != "Happy" {
+		t.Errorf("question emotion = %q from %q, want %q from %q", q.Emotion, q.RawEmotion, "joy", "Happy")
+	}
+	want := []models.EmotionResult{
+		{Emotion: "sadness", RawEmotion: "sad", Valence: -0.7, Arousal: -0.4},
+		{Emotion: models.EmotionUnknown, RawEmotion: "bored"},
+	}
+	if !reflect.DeepEqual(q.EmotionResults, want) {
+		t.Errorf("emotions = %+v, want %+v", q.EmotionResults, want)
+	}
+	if !reflect.DeepEqual(unknown, []string{"bored"}) {
+		t.Errorf("unknown = %v, want [bored]", unknown)
+	}
+}
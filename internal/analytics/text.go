@@ -0,0 +1,27 @@
+package analytics
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tokenize lowercases text and splits it into words, keeping apostrophes
+// inside words ("don't") and dropping all other punctuation.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
+
+// hasPhraseAt reports whether phrase occurs in tokens starting at i.
+func hasPhraseAt(tokens []string, i int, phrase []string) bool {
+	if len(phrase) == 0 || i+len(phrase) > len(tokens) {
+		return false
+	}
+	for j, word := range phrase {
+		if tokens[i+j] != word {
+			return false
+		}
+	}
+	return true
+}
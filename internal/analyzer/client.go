@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+type Question struct {
+	Question  string `json:"question"`
+	PublicID  string `json:"public_id"`
+	VideoLink string `json:"video_link"`
+}
+
+type Request struct {
+	Questions []Question `json:"questions"`
+}
+
+type Response struct {
+	Result models.Result `json:"result"`
+	// Raw is the response body as received.
+	Raw []byte `json:"-"`
+}
+
+// maxBodySize bounds how much of a response is read and kept for errors.
+const maxBodySize = 8 << 20
+
+// Error is a failed call to the analyzer. StatusCode is 0 when no
+// response was received; Body holds what the analyzer answered, e.g. the
+// validation details of a 422.
+type Error struct {
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	if e.Body != "" {
+		return fmt.Sprintf("API request failed with status code %d. %s", e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("API request failed with status code %d", e.StatusCode)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Client talks to the video analysis service.
+type Client interface {
+	ProcessInterview(ctx context.Context, req *Request) (*Response, error)
+}
+
+type httpClient struct {
+	url    string
+	client *http.Client
+}
+
+func New(cfg *config.Video) Client {
+	return &httpClient{
+		url: cfg.Url,
+		client: &http.Client{
+			// traced, passing the trace context on to the analyzer
+			Transport: otelhttp.NewTransport(
+				&http.Transport{
+					Dial: dialTimeout,
+				},
+				otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+					return r.Method + " " + r.URL.Path
+				}),
+			),
+		},
+	}
+}
+
+func dialTimeout(network, addr string) (net.Conn, error) {
+	return net.DialTimeout(network, addr, 600*time.Second)
+}
+
+func (c *httpClient) ProcessInterview(ctx context.Context, data *Request) (*Response, error) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal data to JSON: %v", err)
+	}
+	// Create a request body with the JSON data
+	body := bytes.NewReader(jsonData)
+
+	// Send a POST request to the API endpoint
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/process_interview", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, &Error{Err: fmt.Errorf("failed to send request to API: %w", err)}
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, &Error{StatusCode: resp.StatusCode, Err: fmt.Errorf("failed to read response body: %v", err)}
+	}
+	// Check the response status code
+	if resp.StatusCode != http.StatusOK {
+		return nil, &Error{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	var responseData Response
+	err = json.Unmarshal(respBody, &responseData)
+	if err != nil {
+		return nil, &Error{StatusCode: resp.StatusCode, Body: string(respBody), Err: fmt.Errorf("failed to unmarshal response body: %v", err)}
+	}
+	responseData.Raw = respBody
+
+	return &responseData, nil
+}
@@ -0,0 +1,145 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Fixture is a recorded call to the analyzer. Response is the body as
+// received, which is an error message unless StatusCode is 200.
+type Fixture struct {
+	QuestionIDs []string        `json:"question_ids"`
+	Request     *Request        `json:"request"`
+	StatusCode  int             `json:"status_code"`
+	Response    json.RawMessage `json:"response"`
+}
+
+type recorder struct {
+	client Client
+	dir    string
+	mu     sync.Mutex
+}
+
+// NewRecorder passes the calls on to client and saves every answered one
+// to a fixture file in dir, named after the question IDs of the request.
+// Calls that got no response are not recorded.
+func NewRecorder(client Client, dir string) (Client, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &recorder{
+		client: client,
+		dir:    dir,
+	}, nil
+}
+
+func (r *recorder) ProcessInterview(ctx context.Context, req *Request) (*Response, error) {
+	res, err := r.client.ProcessInterview(ctx, req)
+
+	fixture := &Fixture{
+		QuestionIDs: questionIDs(req),
+		Request:     req,
+	}
+	var analyzerErr *Error
+	switch {
+	case err == nil:
+		fixture.StatusCode = http.StatusOK
+		fixture.Response = res.Raw
+	case errors.As(err, &analyzerErr) && analyzerErr.StatusCode != 0:
+		fixture.StatusCode = analyzerErr.StatusCode
+		fixture.Response, _ = json.Marshal(analyzerErr.Body)
+	default:
+		return res, err
+	}
+
+	data, marshalErr := json.MarshalIndent(fixture, "", "  ")
+	if marshalErr == nil {
+		r.mu.Lock()
+		marshalErr = os.WriteFile(filepath.Join(r.dir, fixtureName(fixture.QuestionIDs)), data, 0o644)
+		r.mu.Unlock()
+	}
+	if marshalErr != nil {
+		return nil, fmt.Errorf("failed to record analyzer fixture: %v", marshalErr)
+	}
+	return res, err
+}
+
+type replayer struct {
+	fixtures map[string]*Fixture
+}
+
+// NewReplayer serves the fixtures in dir instead of calling the analyzer.
+// A request is matched to the fixture recorded for the same question IDs,
+// in any order.
+func NewReplayer(dir string) (Client, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	fixtures := make(map[string]*Fixture, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		fixture := &Fixture{}
+		if err = json.Unmarshal(data, fixture); err != nil {
+			return nil, fmt.Errorf("failed to read analyzer fixture %s: %v", path, err)
+		}
+		fixtures[fixtureKey(fixture.QuestionIDs)] = fixture
+	}
+	return &replayer{fixtures: fixtures}, nil
+}
+
+func (r *replayer) ProcessInterview(ctx context.Context, req *Request) (*Response, error) {
+	ids := questionIDs(req)
+	fixture, ok := r.fixtures[fixtureKey(ids)]
+	if !ok {
+		return nil, &Error{Err: fmt.Errorf("no analyzer fixture for questions %v", ids)}
+	}
+	if fixture.StatusCode != http.StatusOK {
+		var body string
+		if err := json.Unmarshal(fixture.Response, &body); err != nil {
+			body = string(fixture.Response)
+		}
+		return nil, &Error{StatusCode: fixture.StatusCode, Body: body}
+	}
+
+	var responseData Response
+	if err := json.Unmarshal(fixture.Response, &responseData); err != nil {
+		return nil, &Error{StatusCode: fixture.StatusCode, Body: string(fixture.Response), Err: fmt.Errorf("failed to unmarshal response body: %v", err)}
+	}
+	responseData.Raw = fixture.Response
+	return &responseData, nil
+}
+
+// questionIDs returns the sorted question IDs of req.
+func questionIDs(req *Request) []string {
+	ids := make([]string, 0, len(req.Questions))
+	for _, q := range req.Questions {
+		ids = append(ids, q.PublicID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func fixtureKey(ids []string) string {
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+func fixtureName(ids []string) string {
+	sum := sha256.Sum256([]byte(fixtureKey(ids)))
+	return hex.EncodeToString(sum[:8]) + ".json"
+}
@@ -0,0 +1,184 @@
+package analyzer
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+)
+
+// Ticket identifies who a call to the analyzer is made for.
+type Ticket struct {
+	Company  string
+	Priority int
+}
+
+type waiter struct {
+	ticket  Ticket
+	ready   chan struct{}
+	granted bool
+}
+
+// Limiter bounds the calls to the analyzer in flight, globally and per
+// company. Calls over the limits wait for a slot in a queue ordered by
+// priority and then by arrival; a call that can't be queued or waits
+// longer than the queue timeout is rejected. The limits are kept in
+// process and hold per replica: n replicas make up to n times MaxInFlight
+// calls at once.
+type Limiter struct {
+	cfg *config.AnalyzerLimits
+
+	mu       sync.Mutex
+	inFlight int
+	running  map[string]int
+	queued   map[string]int
+	queue    []*waiter
+	rejected int64
+	timedOut int64
+}
+
+func NewLimiter(cfg *config.AnalyzerLimits) *Limiter {
+	return &Limiter{
+		cfg:     cfg,
+		running: make(map[string]int),
+		queued:  make(map[string]int),
+	}
+}
+
+// Acquire waits for a slot and returns the function releasing it. It fails
+// with models.ErrAnalysisRateLimited when the company has too many calls
+// waiting and with models.ErrAnalyzerBusy when the queue is full or the
+// wait times out. A wait ended by ctx returns its error.
+func (l *Limiter) Acquire(ctx context.Context, ticket Ticket) (func(), error) {
+	l.mu.Lock()
+	if l.available(ticket.Company) {
+		l.start(ticket.Company)
+		l.mu.Unlock()
+		return l.releaser(ticket.Company), nil
+	}
+	if l.queued[ticket.Company] >= l.cfg.MaxCompanyQueue {
+		l.rejected++
+		l.mu.Unlock()
+		return nil, models.ErrAnalysisRateLimited
+	}
+	if len(l.queue) >= l.cfg.MaxQueue {
+		l.rejected++
+		l.mu.Unlock()
+		return nil, models.ErrAnalyzerBusy
+	}
+	w := &waiter{ticket: ticket, ready: make(chan struct{})}
+	l.enqueue(w)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.cfg.QueueTimeout)
+	defer timer.Stop()
+	err := models.ErrAnalyzerBusy
+	select {
+	case <-w.ready:
+		return l.releaser(ticket.Company), nil
+	case <-timer.C:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w.granted {
+		return l.releaser(ticket.Company), nil
+	}
+	l.remove(w)
+	if err == models.ErrAnalyzerBusy {
+		l.timedOut++
+	}
+	return nil, err
+}
+
+// Stats returns a snapshot of the calls in flight and waiting.
+func (l *Limiter) Stats() *models.AnalyzerQueue {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := &models.AnalyzerQueue{
+		InFlight:    l.inFlight,
+		Queued:      len(l.queue),
+		MaxInFlight: l.cfg.MaxInFlight,
+		MaxQueue:    l.cfg.MaxQueue,
+		Rejected:    l.rejected,
+		TimedOut:    l.timedOut,
+		Companies:   make(map[string]*models.CompanyQueue),
+	}
+	for company, n := range l.running {
+		stats.Companies[company] = &models.CompanyQueue{InFlight: n}
+	}
+	for company, n := range l.queued {
+		if stats.Companies[company] == nil {
+			stats.Companies[company] = &models.CompanyQueue{}
+		}
+		stats.Companies[company].Queued = n
+	}
+	return stats
+}
+
+func (l *Limiter) available(company string) bool {
+	return l.inFlight < l.cfg.MaxInFlight && l.running[company] < l.cfg.MaxPerCompany
+}
+
+func (l *Limiter) start(company string) {
+	l.inFlight++
+	l.running[company]++
+}
+
+func (l *Limiter) releaser(company string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.release(company) })
+	}
+}
+
+// release frees the slot of company and hands the free slots to the
+// waiting calls in queue order, skipping companies at their own limit.
+func (l *Limiter) release(company string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	if l.running[company]--; l.running[company] <= 0 {
+		delete(l.running, company)
+	}
+
+	for i := 0; i < len(l.queue) && l.inFlight < l.cfg.MaxInFlight; {
+		w := l.queue[i]
+		if !l.available(w.ticket.Company) {
+			i++
+			continue
+		}
+		l.remove(w)
+		l.start(w.ticket.Company)
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+// enqueue puts w after the waiters of the same or a higher priority.
+func (l *Limiter) enqueue(w *waiter) {
+	i := sort.Search(len(l.queue), func(i int) bool {
+		return l.queue[i].ticket.Priority < w.ticket.Priority
+	})
+	l.queue = append(l.queue, nil)
+	copy(l.queue[i+1:], l.queue[i:])
+	l.queue[i] = w
+	l.queued[w.ticket.Company]++
+}
+
+func (l *Limiter) remove(w *waiter) {
+	for i := range l.queue {
+		if l.queue[i] == w {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+			break
+		}
+	}
+	if l.queued[w.ticket.Company]--; l.queued[w.ticket.Company] <= 0 {
+		delete(l.queued, w.ticket.Company)
+	}
+}
@@ -0,0 +1,203 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+)
+
+func testLimiter(maxInFlight, maxPerCompany int, queueTimeout time.Duration) *Limiter {
+	return NewLimiter(&config.AnalyzerLimits{
+		MaxInFlight:     maxInFlight,
+		MaxPerCompany:   maxPerCompany,
+		MaxQueue:        3,
+		MaxCompanyQueue: 2,
+		QueueTimeout:    queueTimeout,
+	})
+}
+
+func mustAcquire(t *testing.T, l *Limiter, ticket Ticket) func() {
+	t.Helper()
+	release, err := l.Acquire(context.Background(), ticket)
+	if err != nil {
+		t.Fatalf("Acquire(%+v) error = %v", ticket, err)
+	}
+	return release
+}
+
+// waitQueued waits until n calls wait in l.
+func waitQueued(t *testing.T, l *Limiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for l.Stats().Queued != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("queued = %d, want %d", l.Stats().Queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// acquireAsync acquires a slot for ticket in the background, reports the
+// company on granted and releases the slot at once.
+func acquireAsync(l *Limiter, ticket Ticket, granted chan<- string) {
+	go func() {
+		release, err := l.Acquire(context.Background(), ticket)
+		if err != nil {
+			granted <- err.Error()
+			return
+		}
+		granted <- ticket.Company
+		release()
+	}()
+}
+
+func receive(t *testing.T, granted <-chan string) string {
+	t.Helper()
+	select {
+	case company := <-granted:
+		return company
+	case <-time.After(time.Second):
+		t.Fatal("no slot was granted")
+		return ""
+	}
+}
+
+func TestLimiterPriority(t *testing.T) {
+	l := testLimiter(1, 1, time.Minute)
+	release := mustAcquire(t, l, Ticket{Company: "a"})
+
+	granted := make(chan string, 3)
+	acquireAsync(l, Ticket{Company: "b"}, granted)
+	waitQueued(t, l, 1)
+	acquireAsync(l, Ticket{Company: "c", Priority: 10}, granted)
+	waitQueued(t, l, 2)
+	acquireAsync(l, Ticket{Company: "d"}, granted)
+	waitQueued(t, l, 3)
+
+	release()
+	for _, want := range []string{"c", "b", "d"} {
+		if got := receive(t, granted); got != want {
+			t.Errorf("granted %s, want %s", got, want)
+		}
+	}
+	waitQueued(t, l, 0)
+	if n := l.Stats().InFlight; n != 0 {
+		t.Errorf("in flight = %d, want 0", n)
+	}
+}
+
+func TestLimiterCompanyCap(t *testing.T) {
+	l := testLimiter(2, 1, time.Minute)
+	releaseA := mustAcquire(t, l, Ticket{Company: "a"})
+	releaseB := mustAcquire(t, l, Ticket{Company: "b"})
+
+	granted := make(chan string, 2)
+	acquireAsync(l, Ticket{Company: "a"}, granted)
+	waitQueued(t, l, 1)
+	acquireAsync(l, Ticket{Company: "c"}, granted)
+	waitQueued(t, l, 2)
+
+	// the slot of b goes to c, past the call of a that waits for its own
+	releaseB()
+	if got := receive(t, granted); got != "c" {
+		t.Errorf("granted %s, want c", got)
+	}
+	waitQueued(t, l, 1)
+	if stats := l.Stats(); stats.Companies["a"] == nil || stats.Companies["a"].Queued != 1 {
+		t.Errorf("companies = %+v, want a call of a queued", stats.Companies)
+	}
+
+	releaseA()
+	if got := receive(t, granted); got != "a" {
+		t.Errorf("granted %s, want a", got)
+	}
+}
+
+func TestLimiterCompanyAtCapDoesNotBlockOthers(t *testing.T) {
+	l := testLimiter(3, 1, time.Minute)
+	release := mustAcquire(t, l, Ticket{Company: "a"})
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	releaseB, err := l.Acquire(ctx, Ticket{Company: "b"})
+	if err != nil {
+		t.Fatalf("Acquire() for another company error = %v", err)
+	}
+	releaseB()
+}
+
+func TestLimiterTimeout(t *testing.T) {
+	l := testLimiter(1, 1, 10*time.Millisecond)
+	release := mustAcquire(t, l, Ticket{Company: "a"})
+
+	if _, err := l.Acquire(context.Background(), Ticket{Company: "b"}); !errors.Is(err, models.ErrAnalyzerBusy) {
+		t.Fatalf("Acquire() error = %v, want %v", err, models.ErrAnalyzerBusy)
+	}
+	stats := l.Stats()
+	if stats.Queued != 0 || stats.TimedOut != 1 || stats.Companies["b"] != nil {
+		t.Errorf("stats = %+v, want the timed out call removed and counted", stats)
+	}
+
+	release()
+	if n := l.Stats().InFlight; n != 0 {
+		t.Errorf("in flight = %d, want the slot not handed to the removed call", n)
+	}
+}
+
+func TestLimiterCancel(t *testing.T) {
+	l := testLimiter(1, 1, time.Minute)
+	release := mustAcquire(t, l, Ticket{Company: "a"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := l.Acquire(ctx, Ticket{Company: "b"})
+		done <- err
+	}()
+	waitQueued(t, l, 1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire() error = %v, want %v", err, context.Canceled)
+	}
+	stats := l.Stats()
+	if stats.Queued != 0 || stats.TimedOut != 0 || stats.Companies["b"] != nil {
+		t.Errorf("stats = %+v, want the cancelled call removed and not counted as timed out", stats)
+	}
+
+	release()
+	release()
+	if n := l.Stats().InFlight; n != 0 {
+		t.Errorf("in flight = %d, want 0 after releasing twice", n)
+	}
+}
+
+func TestLimiterRejects(t *testing.T) {
+	l := testLimiter(1, 1, time.Minute)
+	release := mustAcquire(t, l, Ticket{Company: "a"})
+
+	granted := make(chan string, 3)
+	acquireAsync(l, Ticket{Company: "b"}, granted)
+	acquireAsync(l, Ticket{Company: "b"}, granted)
+	waitQueued(t, l, 2)
+	if _, err := l.Acquire(context.Background(), Ticket{Company: "b"}); !errors.Is(err, models.ErrAnalysisRateLimited) {
+		t.Errorf("Acquire() over the company queue error = %v, want %v", err, models.ErrAnalysisRateLimited)
+	}
+	acquireAsync(l, Ticket{Company: "c"}, granted)
+	waitQueued(t, l, 3)
+	if _, err := l.Acquire(context.Background(), Ticket{Company: "d"}); !errors.Is(err, models.ErrAnalyzerBusy) {
+		t.Errorf("Acquire() over the queue error = %v, want %v", err, models.ErrAnalyzerBusy)
+	}
+	if n := l.Stats().Rejected; n != 2 {
+		t.Errorf("rejected = %d, want 2", n)
+	}
+
+	release()
+	for i := 0; i < 3; i++ {
+		receive(t, granted)
+	}
+}
@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+)
+
+// outcomes of a call to the analyzer
+const (
+	OutcomeSuccess   = "success"
+	OutcomeInvalid   = "invalid"
+	OutcomeRejected  = "rejected"
+	OutcomeMalformed = "malformed"
+	OutcomeNoAnswer  = "no_answer"
+	OutcomeCanceled  = "canceled"
+)
+
+type observedClient struct {
+	client  Client
+	observe func(outcome string, d time.Duration)
+}
+
+// NewObserved passes the calls on to client and reports the outcome and
+// duration of each to observe.
+func NewObserved(client Client, observe func(outcome string, d time.Duration)) Client {
+	return &observedClient{
+		client:  client,
+		observe: observe,
+	}
+}
+
+func (c *observedClient) ProcessInterview(ctx context.Context, req *Request) (*Response, error) {
+	start := time.Now()
+	res, err := c.client.ProcessInterview(ctx, req)
+	c.observe(Outcome(err), time.Since(start))
+	return res, err
+}
+
+// Outcome classifies the error of a call to the analyzer: no error, a
+// response breaking the contract, a non-200 status, an unreadable body, no
+// response at all or a call given up by the caller.
+func Outcome(err error) string {
+	var analyzerErr *Error
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return OutcomeCanceled
+	case errors.Is(err, models.ErrInvalidAnalysis):
+		return OutcomeInvalid
+	case !errors.As(err, &analyzerErr) || analyzerErr.StatusCode == 0:
+		return OutcomeNoAnswer
+	case analyzerErr.StatusCode != http.StatusOK:
+		return OutcomeRejected
+	}
+	return OutcomeMalformed
+}
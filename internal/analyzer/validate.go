@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+)
+
+// requiredFields tells for every field that can be required whether a
+// question result has it.
+var requiredFields = map[string]func(q *models.QuestionResult) bool{
+	"question_type":   func(q *models.QuestionResult) bool { return q.QuestionType != "" },
+	"evaluation":      func(q *models.QuestionResult) bool { return q.Evaluation != "" },
+	"answer":          func(q *models.QuestionResult) bool { return q.Answer != "" },
+	"language":        func(q *models.QuestionResult) bool { return q.Language != "" },
+	"emotion":         func(q *models.QuestionResult) bool { return q.Emotion != "" },
+	"video_public_id": func(q *models.QuestionResult) bool { return q.VideoPublicID != "" },
+	"emotion_results": func(q *models.QuestionResult) bool { return len(q.EmotionResults) != 0 },
+	"words":           func(q *models.QuestionResult) bool { return len(q.Words) != 0 },
+}
+
+type validatingClient struct {
+	client   Client
+	contract *config.AnalyzerContract
+}
+
+// NewValidating checks every response of client against the request and
+// the contract. A response breaking it is returned as an *Error holding
+// the response body and wrapping models.ErrInvalidAnalysis.
+func NewValidating(client Client, contract *config.AnalyzerContract) (Client, error) {
+	if contract.MaxScore <= contract.MinScore {
+		return nil, fmt.Errorf("invalid score range %d to %d of analyzer results", contract.MinScore, contract.MaxScore)
+	}
+	for _, field := range contract.RequiredFields {
+		if _, ok := requiredFields[field]; !ok {
+			return nil, fmt.Errorf("unknown required field %q of analyzer results", field)
+		}
+	}
+	return &validatingClient{
+		client:   client,
+		contract: contract,
+	}, nil
+}
+
+func (c *validatingClient) ProcessInterview(ctx context.Context, req *Request) (*Response, error) {
+	res, err := c.client.ProcessInterview(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if problems := Validate(c.contract, req, res); len(problems) != 0 {
+		return nil, &Error{
+			StatusCode: http.StatusOK,
+			Body:       string(res.Raw),
+			Err:        fmt.Errorf("%w: %s", models.ErrInvalidAnalysis, strings.Join(problems, "; ")),
+		}
+	}
+	return res, nil
+}
+
+// Validate returns what is wrong with the response to req: every question
+// sent must come back exactly once and nothing else, with its score within
+// the bounds, the required fields set and its emotions within the answer.
+// The overall score is the sum of the question scores.
+func Validate(contract *config.AnalyzerContract, req *Request, res *Response) []string {
+	problems := make([]string, 0)
+	sent := make(map[string]bool, len(req.Questions))
+	for _, q := range req.Questions {
+		sent[q.PublicID] = false
+	}
+
+	for i := range res.Result.Questions {
+		q := &res.Result.Questions[i]
+		returned, ok := sent[q.PublicID]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("question %q was not sent", q.PublicID))
+			continue
+		case returned:
+			problems = append(problems, fmt.Sprintf("question %s is returned more than once", q.PublicID))
+			continue
+		}
+		sent[q.PublicID] = true
+
+		if q.Score < contract.MinScore || q.Score > contract.MaxScore {
+			problems = append(problems, fmt.Sprintf("score %d of question %s is out of [%d, %d]",
+				q.Score, q.PublicID, contract.MinScore, contract.MaxScore))
+		}
+		for _, field := range contract.RequiredFields {
+			if !requiredFields[field](q) {
+				problems = append(problems, fmt.Sprintf("question %s has no %s", q.PublicID, field))
+			}
+		}
+		if q.Duration < 0 {
+			problems = append(problems, fmt.Sprintf("question %s has a negative duration", q.PublicID))
+		}
+		for _, e := range q.EmotionResults {
+			if e.ExactTime < 0 || e.Duration < 0 {
+				problems = append(problems, fmt.Sprintf("question %s has an emotion at a negative time", q.PublicID))
+				break
+			}
+			if q.Duration > 0 && e.ExactTime+e.Duration > q.Duration {
+				problems = append(problems, fmt.Sprintf("question %s has an emotion past its duration", q.PublicID))
+				break
+			}
+		}
+		for _, at := range q.MultipleFaces {
+			if at < 0 {
+				problems = append(problems, fmt.Sprintf("question %s has faces at a negative time", q.PublicID))
+				break
+			}
+		}
+	}
+	for _, q := range req.Questions {
+		if !sent[q.PublicID] {
+			problems = append(problems, fmt.Sprintf("question %s is missing", q.PublicID))
+		}
+	}
+
+	n := len(res.Result.Questions)
+	if res.Result.Score < contract.MinScore*n || res.Result.Score > contract.MaxScore*n {
+		problems = append(problems, fmt.Sprintf("overall score %d is out of [%d, %d]",
+			res.Result.Score, contract.MinScore*n, contract.MaxScore*n))
+	}
+	return problems
+}
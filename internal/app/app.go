@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,9 +12,15 @@ import (
 
 	"github.com/Zhiyenbek/sp-interview-main-service/config"
 	handler "github.com/Zhiyenbek/sp-interview-main-service/internal/handler/http"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/metrics"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/notification"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/outbox"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/progress"
 	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
 	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository/connection"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/scheduler"
 	"github.com/Zhiyenbek/sp-interview-main-service/internal/service"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/tracing"
 	"go.uber.org/zap"
 )
 
@@ -29,15 +36,62 @@ func Run() error {
 		return err
 	}
 
+	shutdownTracing, err := tracing.New(context.Background(), cfg.Tracing)
+	if err != nil {
+		sugar.Errorf("error while setting up tracing: %v", err)
+		return err
+	}
+
 	db, err := connection.NewPostgresDB(cfg.DB)
 	if err != nil {
 		sugar.Errorf("error while creating database: %v", err)
 		return err
 	}
 	defer db.Close()
+	notifier, err := notification.New(cfg.SMTP, sugar)
+	if err != nil {
+		sugar.Errorf("error while creating notifier: %v", err)
+		return err
+	}
 	repos := repository.New(db, cfg, sugar)
-	services := service.New(repos, sugar, cfg)
-	handlers := handler.New(services, sugar, cfg)
+	m, err := metrics.New(cfg.Analysis.Contract.MinScore, cfg.Analysis.Contract.MaxScore)
+	if err != nil {
+		sugar.Errorf("error while creating metrics: %v", err)
+		return err
+	}
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	services, err := service.New(background, repos, sugar, cfg, notifier, m)
+	if err != nil {
+		sugar.Errorf("error while creating services: %v", err)
+		return err
+	}
+	relay := outbox.NewRelay(cfg.Outbox, repos.OutboxRepository, sugar)
+	relay.Subscribe("notifications", services.NotifyInterviewEvent)
+	relay.Subscribe("webhooks", services.PublishInterviewEvent)
+
+	jobs := scheduler.New(cfg.Scheduler, repos.LockRepository, sugar)
+	jobs.AddEvery("outbox_relay", cfg.Outbox.PollInterval, relay.Relay)
+	jobs.Add("invitation_reminders", services.RemindInvitations)
+	jobs.Add("invitation_expiry", services.ExpireInvitations)
+	jobs.Add("interview_reminders", services.RemindInterviews)
+	jobs.Add("interview_expiry", services.ExpireInterviews)
+	jobs.Add("webhook_deliveries", services.DeliverWebhooks)
+	hub := progress.NewHub(repos.ProgressRepository, sugar)
+	err = m.Register(
+		metrics.NewPoolCollector(db),
+		metrics.NewQueueCollector(services.GetAnalyzerQueue),
+		metrics.NewJobsCollector(jobs.Runs),
+	)
+	if err != nil {
+		sugar.Errorf("error while registering metrics: %v", err)
+		return err
+	}
+
+	jobs.Start(background)
+	hub.Start(background)
+
+	handlers := handler.New(services, jobs, hub, m, sugar, cfg)
 
 	port, ok := os.LookupEnv("PORT")
 	if !ok {
@@ -46,9 +100,13 @@ func Run() error {
 
 	}
 
+	// requests are cancelled when they outlast the graceful shutdown
+	requests, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 	srv := http.Server{
-		Addr:    ":" + port,
-		Handler: handlers.InitRoutes(),
+		Addr:        ":" + port,
+		Handler:     handlers.InitRoutes(),
+		BaseContext: func(net.Listener) context.Context { return requests },
 	}
 	errChan := make(chan error, 1)
 	go func(errChan chan<- error) {
@@ -71,10 +129,26 @@ func Run() error {
 
 	log.Println("Shutting down server...")
 
+	// stopping the hub ends the progress streams, which Shutdown would wait
+	// for, and cancels the analyses running in the background
+	stopBackground()
+	hub.Wait()
+
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.App.TimeOut)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		sugar.Errorf("WARN: Server forced to shutdown: %v", err)
+		cancelRequests()
+	}
+	jobs.Wait()
+	// the background work still records its outcome in the database
+	services.Wait()
+	// spans of the requests just finished are still being exported, with a
+	// timeout of their own as the shutdown may have used up ctx
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), cfg.App.TimeOut)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		sugar.Errorf("WARN: could not flush traces: %v", err)
 	}
 	return nil
 
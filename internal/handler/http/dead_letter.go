@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type FailedAnalysisIDs struct {
+	IDs []string `json:"ids" binding:"required"`
+}
+
+func (h *handler) GetFailedAnalyses(c *gin.Context) {
+	args, err := parseSearchArgs(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+		return
+	}
+
+	res, err := h.service.DeadLettersService.GetFailedAnalyses(c.Query("status"), args)
+	if err != nil {
+		h.sendDeadLetterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) GetFailedAnalysis(c *gin.Context) {
+	res, err := h.service.DeadLettersService.GetFailedAnalysis(c.Param("id"))
+	if err != nil {
+		h.sendDeadLetterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) RetryFailedAnalyses(c *gin.Context) {
+	req := &FailedAnalysisIDs{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		h.logger.Errorf("Failed to parse request body when retrying failed analyses: %s\n", err.Error())
+		c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+		return
+	}
+
+	res, err := h.service.DeadLettersService.RetryFailedAnalyses(req.IDs)
+	if err != nil {
+		h.sendDeadLetterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, sendResponse(0, res, nil))
+}
+
+func (h *handler) DiscardFailedAnalyses(c *gin.Context) {
+	req := &FailedAnalysisIDs{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		h.logger.Errorf("Failed to parse request body when discarding failed analyses: %s\n", err.Error())
+		c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+		return
+	}
+
+	discarded, err := h.service.DeadLettersService.DiscardFailedAnalyses(req.IDs)
+	if err != nil {
+		h.sendDeadLetterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, gin.H{"discarded": discarded}, nil))
+}
+
+func (h *handler) sendDeadLetterError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+	case errors.Is(err, models.ErrDeadLetterNotFound):
+		c.JSON(http.StatusNotFound, sendResponse(-1, nil, models.ErrDeadLetterNotFound))
+	default:
+		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+	}
+}
@@ -1,43 +1,124 @@
 package handler
 
 import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
 	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/metrics"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/progress"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/scheduler"
 	"github.com/Zhiyenbek/sp-interview-main-service/internal/service"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"go.uber.org/zap"
 )
 
 type handler struct {
-	service *service.Service
-	cfg     *config.Configs
-	logger  *zap.SugaredLogger
+	service   *service.Service
+	scheduler *scheduler.Scheduler
+	progress  *progress.Hub
+	metrics   *metrics.Metrics
+	cfg       *config.Configs
+	logger    *zap.SugaredLogger
 }
 
 type Handler interface {
 	InitRoutes() *gin.Engine
 }
 
-func New(services *service.Service, logger *zap.SugaredLogger, cfg *config.Configs) Handler {
+func New(services *service.Service, jobs *scheduler.Scheduler, hub *progress.Hub, m *metrics.Metrics, logger *zap.SugaredLogger, cfg *config.Configs) Handler {
 	return &handler{
-		service: services,
-		cfg:     cfg,
-		logger:  logger,
+		service:   services,
+		scheduler: jobs,
+		progress:  hub,
+		metrics:   m,
+		cfg:       cfg,
+		logger:    logger,
 	}
 }
 
 func (h *handler) InitRoutes() *gin.Engine {
 	router := gin.Default()
 	router.Use(cors.Default())
+	router.Use(otelgin.Middleware(h.cfg.Tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != h.cfg.Metrics.Path
+	})))
+	router.Use(h.metrics.Middleware())
+	router.Use(h.deadline)
+
+	router.GET(h.cfg.Metrics.Path, gin.WrapH(h.metrics.Handler()))
 
 	router.POST("/interviews/:id/videos")
 	router.POST("/interview/:id/result", h.CreateInterviewResult)
+	router.POST("/interview/:id/integrity_events", h.candidate, h.AddIntegrityEvents)
+	router.POST("/interview/:id/start", h.candidate, h.StartInterview)
+	router.GET("/interview/:interview_public_id/next_question", h.candidate, h.GetNextQuestion)
+	router.GET("/interview/:interview_public_id/progress", h.StreamProgress)
+	router.POST("/interview/:id/questions/:question_id/start", h.candidate, h.StartQuestion)
+	router.POST("/interview/:id/questions/:question_id/answer", h.candidate, h.SubmitAnswer)
 	router.POST("/question/:id/video", h.AddVideoToQuestion)
+	router.GET("/question/:id/key_points", h.GetKeyPoints)
+	router.PUT("/question/:id/key_points", h.SetKeyPoints)
 	router.GET("/interviews", h.GetInterviews)
 	router.GET("/interview/:interview_public_id", h.GetInterviewByPublicID)
+	router.GET("/companies/:company_id/transcripts/search", h.SearchTranscripts)
+	router.GET("/positions/:id/similarities", h.GetPositionSimilarities)
+	router.POST("/positions/:id/invitations", h.CreateInvitation)
+	router.GET("/positions/:id/invitations", h.GetPositionInvitations)
+	router.POST("/invitations/:id/resend", h.ResendInvitation)
+	router.POST("/invitations/:id/revoke", h.RevokeInvitation)
+	router.GET("/invitation/:token", h.OpenInvitation)
+	router.POST("/invitation/:token/accept", h.AcceptInvitation)
+	router.PUT("/interview/:id/score", h.OverrideScore)
+	router.POST("/interview/:id/questions/:question_id/analyze", h.ReanalyzeQuestion)
+	router.GET("/interview/:interview_public_id/question_results", h.GetQuestionAnalyses)
+	router.GET("/interview/:interview_public_id/versions", h.GetResultVersions)
+	router.POST("/companies/:company_id/webhooks", h.CreateWebhookEndpoint)
+	router.GET("/companies/:company_id/webhooks", h.GetCompanyWebhookEndpoints)
+	router.DELETE("/webhooks/:id", h.DeleteWebhookEndpoint)
+	router.GET("/webhooks/:id/deliveries", h.GetWebhookDeliveries)
+	router.GET("/webhook_deliveries/:id", h.GetWebhookDelivery)
+	router.POST("/webhook_deliveries/:id/replay", h.ReplayWebhookDelivery)
+	router.GET("/admin/scheduler", h.GetSchedulerRuns)
+	router.GET("/admin/analyzer", h.GetAnalyzerQueue)
+	router.GET("/admin/failed_analyses", h.GetFailedAnalyses)
+	router.GET("/admin/failed_analyses/:id", h.GetFailedAnalysis)
+	router.POST("/admin/failed_analyses/retry", h.RetryFailedAnalyses)
+	router.POST("/admin/failed_analyses/discard", h.DiscardFailedAnalyses)
 	return router
 }
 
+// deadline bounds the request context by the timeout of its route, so that
+// queries and analyzer calls stop once it passes or the client goes away.
+func (h *handler) deadline(c *gin.Context) {
+	timeout := h.routeTimeout(c.FullPath())
+	if timeout <= 0 {
+		c.Next()
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+}
+
+// routeTimeout returns how long a request to route may take; progress
+// streams are not bounded.
+func (h *handler) routeTimeout(route string) time.Duration {
+	switch route {
+	case "/interview/:interview_public_id/progress":
+		return 0
+	case "/interview/:id/result", "/interview/:id/questions/:question_id/analyze":
+		return h.cfg.App.AnalysisTimeout
+	}
+	return h.cfg.App.RequestTimeout
+}
+
 func sendResponse(status int, data interface{}, err error) gin.H {
 	var errResponse gin.H
 	if err != nil {
@@ -54,3 +135,26 @@ func sendResponse(status int, data interface{}, err error) gin.H {
 		"error":  errResponse,
 	}
 }
+
+func parseSearchArgs(c *gin.Context) (*models.SearchArgs, error) {
+	args := &models.SearchArgs{
+		Search:   c.Query("search"),
+		PageNum:  models.DefaultPageNum,
+		PageSize: models.DefaultPageSize,
+	}
+	if v := c.Query("page_num"); v != "" {
+		pageNum, err := strconv.Atoi(v)
+		if err != nil || pageNum < 1 {
+			return nil, models.ErrInvalidInput
+		}
+		args.PageNum = pageNum
+	}
+	if v := c.Query("page_size"); v != "" {
+		pageSize, err := strconv.Atoi(v)
+		if err != nil || pageSize < 1 {
+			return nil, models.ErrInvalidInput
+		}
+		args.PageSize = pageSize
+	}
+	return args, nil
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type IntegrityEvents struct {
+	Events []*models.IntegrityEvent `json:"events" binding:"required,min=1,dive"`
+}
+
+func (h *handler) AddIntegrityEvents(c *gin.Context) {
+	interviewID := c.Param("id")
+	req := &IntegrityEvents{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		h.logger.Errorf("Failed to parse request body when adding integrity events: %s\n", err.Error())
+		c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+		return
+	}
+
+	err := h.service.IntegrityService.AddIntegrityEvents(interviewID, req.Events)
+	if err != nil {
+		h.sendSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, sendResponse(0, nil, nil))
+}
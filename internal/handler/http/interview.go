@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
 	"github.com/gin-gonic/gin"
@@ -36,9 +38,35 @@ type Video struct {
 
 func (h *handler) CreateInterviewResult(c *gin.Context) {
 	interviewID := c.Param("id")
-	res, err := h.service.CreateInterviewResult(interviewID)
+	key := c.GetHeader("Idempotency-Key")
+	// an evaluated interview is analyzed again only when asked to
+	reanalyze := false
+	if v := c.Query("reanalyze"); v != "" {
+		var err error
+		if reanalyze, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+			return
+		}
+	}
+	res, err := h.service.CreateInterviewResult(c.Request.Context(), interviewID, key, reanalyze)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+		if h.sendAnalyzerBusy(c, err) {
+			return
+		}
+		if errors.Is(err, models.ErrAnalysisRunning) {
+			h.sendAnalysisRunning(c, interviewID, key != "")
+			return
+		}
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			c.JSON(http.StatusGatewayTimeout, sendResponse(-1, nil, models.ErrRequestTimeout))
+		case errors.Is(err, models.ErrInterviewNotFound):
+			c.JSON(http.StatusNotFound, sendResponse(-1, nil, models.ErrInterviewNotFound))
+		case errors.Is(err, models.ErrNotSubmitted):
+			c.JSON(http.StatusConflict, sendResponse(-1, nil, models.ErrNotSubmitted))
+		default:
+			c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+		}
 		return
 	}
 	c.JSON(http.StatusCreated, sendResponse(0, res, nil))
@@ -53,7 +81,7 @@ func (h *handler) AddVideoToQuestion(c *gin.Context) {
 		return
 	}
 
-	err := h.service.InterviewsService.AddVideoToQuestion(questionID, req.InterviewPublicID, req.Video)
+	err := h.service.InterviewsService.AddVideoToQuestion(c.Request.Context(), questionID, req.InterviewPublicID, req.Video)
 	if err != nil {
 		if errors.Is(err, models.ErrQuestionNotFound) {
 			c.JSON(http.StatusNotFound, sendResponse(-1, nil, models.ErrQuestionNotFound))
@@ -66,7 +94,7 @@ func (h *handler) AddVideoToQuestion(c *gin.Context) {
 }
 
 func (h *handler) GetInterviews(c *gin.Context) {
-	res, err := h.service.InterviewsService.GetAllInterviews()
+	res, err := h.service.InterviewsService.GetAllInterviews(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
 		return
@@ -78,8 +106,12 @@ func (h *handler) GetInterviews(c *gin.Context) {
 func (h *handler) GetInterviewByPublicID(c *gin.Context) {
 	publicID := c.Param("interview_public_id")
 
-	res, err := h.service.InterviewsService.GetInterviewByPublicID(publicID)
+	res, err := h.service.InterviewsService.GetInterviewByPublicID(c.Request.Context(), publicID)
 	if err != nil {
+		if errors.Is(err, models.ErrInterviewNotFound) {
+			c.JSON(http.StatusNotFound, sendResponse(-1, nil, models.ErrInterviewNotFound))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
 		return
 	}
@@ -87,3 +119,126 @@ func (h *handler) GetInterviewByPublicID(c *gin.Context) {
 	c.JSON(http.StatusOK, sendResponse(0, res, nil))
 
 }
+
+type ScoreOverride struct {
+	Score  *int   `json:"score" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (h *handler) OverrideScore(c *gin.Context) {
+	interviewID := c.Param("id")
+	req := &ScoreOverride{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		h.logger.Errorf("Failed to parse request body when overriding interview score: %s\n", err.Error())
+		c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+		return
+	}
+
+	res, err := h.service.InterviewsService.OverrideScore(c.Request.Context(), interviewID, *req.Score, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+		case errors.Is(err, models.ErrInterviewNotFound):
+			c.JSON(http.StatusNotFound, sendResponse(-1, nil, models.ErrInterviewNotFound))
+		case errors.Is(err, models.ErrResultNotReady):
+			c.JSON(http.StatusConflict, sendResponse(-1, nil, models.ErrResultNotReady))
+		default:
+			c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) ReanalyzeQuestion(c *gin.Context) {
+	res, err := h.service.InterviewsService.ReanalyzeQuestion(c.Request.Context(), c.Param("id"), c.Param("question_id"))
+	if err != nil {
+		if h.sendAnalyzerBusy(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, models.ErrInterviewNotFound):
+			c.JSON(http.StatusNotFound, sendResponse(-1, nil, models.ErrInterviewNotFound))
+		case errors.Is(err, models.ErrQuestionNotFound):
+			c.JSON(http.StatusNotFound, sendResponse(-1, nil, models.ErrQuestionNotFound))
+		case errors.Is(err, models.ErrResultNotReady):
+			c.JSON(http.StatusConflict, sendResponse(-1, nil, models.ErrResultNotReady))
+		case errors.Is(err, context.DeadlineExceeded):
+			c.JSON(http.StatusGatewayTimeout, sendResponse(-1, nil, models.ErrRequestTimeout))
+		case errors.Is(err, models.ErrAnalysisFailed):
+			c.JSON(http.StatusBadGateway, sendResponse(-1, nil, models.ErrAnalysisFailed))
+		default:
+			c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) GetResultVersions(c *gin.Context) {
+	res, err := h.service.InterviewsService.GetResultVersions(c.Request.Context(), c.Param("interview_public_id"))
+	if err != nil {
+		if errors.Is(err, models.ErrInterviewNotFound) {
+			c.JSON(http.StatusNotFound, sendResponse(-1, nil, models.ErrInterviewNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) GetQuestionAnalyses(c *gin.Context) {
+	res, err := h.service.InterviewsService.GetQuestionAnalyses(c.Request.Context(), c.Param("interview_public_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) GetAnalyzerQueue(c *gin.Context) {
+	c.JSON(http.StatusOK, sendResponse(0, h.service.InterviewsService.GetAnalyzerQueue(), nil))
+}
+
+// sendAnalysisRunning answers a request for a result being created with
+// the progress of the running analysis: accepted when the request has an
+// idempotency key to repeat it with until the result is returned, a
+// conflict otherwise.
+func (h *handler) sendAnalysisRunning(c *gin.Context, publicID string, idempotent bool) {
+	progress, err := h.service.InterviewsService.GetProgress(c.Request.Context(), publicID)
+	if err != nil {
+		h.logger.Errorf("could not get progress of interview %s: %v", publicID, err)
+	}
+	if idempotent {
+		c.JSON(http.StatusAccepted, sendResponse(0, progress, nil))
+		return
+	}
+	c.JSON(http.StatusConflict, sendResponse(-1, progress, models.ErrAnalysisRunning))
+}
+
+// sendAnalyzerBusy answers calls rejected by the analyzer limiter with
+// 429 for a company over its own limit and 503 when the analyzer is busy,
+// telling the client when to retry.
+func (h *handler) sendAnalyzerBusy(c *gin.Context, err error) bool {
+	var status int
+	switch {
+	case errors.Is(err, models.ErrAnalysisRateLimited):
+		status = http.StatusTooManyRequests
+		err = models.ErrAnalysisRateLimited
+	case errors.Is(err, models.ErrAnalyzerBusy):
+		status = http.StatusServiceUnavailable
+		err = models.ErrAnalyzerBusy
+	default:
+		return false
+	}
+	retryAfter := int(h.cfg.Analysis.Limits.RetryAfter.Seconds())
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(status, sendResponse(-1, nil, err))
+	return true
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type InvitationRequest struct {
+	CandidatePublicID string `json:"candidate_public_id" binding:"required"`
+}
+
+func (h *handler) CreateInvitation(c *gin.Context) {
+	positionID := c.Param("id")
+	req := &InvitationRequest{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		h.logger.Errorf("Failed to parse request body when creating invitation: %s\n", err.Error())
+		c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+		return
+	}
+
+	res, err := h.service.InvitationsService.CreateInvitation(positionID, req.CandidatePublicID)
+	if err != nil {
+		h.sendInvitationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, sendResponse(0, res, nil))
+}
+
+func (h *handler) GetPositionInvitations(c *gin.Context) {
+	positionID := c.Param("id")
+
+	res, err := h.service.InvitationsService.GetPositionInvitations(positionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) ResendInvitation(c *gin.Context) {
+	invitationID := c.Param("id")
+
+	res, err := h.service.InvitationsService.ResendInvitation(invitationID)
+	if err != nil {
+		h.sendInvitationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) RevokeInvitation(c *gin.Context) {
+	invitationID := c.Param("id")
+
+	err := h.service.InvitationsService.RevokeInvitation(c.Request.Context(), invitationID)
+	if err != nil {
+		h.sendInvitationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, nil, nil))
+}
+
+func (h *handler) OpenInvitation(c *gin.Context) {
+	token := c.Param("token")
+
+	res, err := h.service.InvitationsService.OpenInvitation(c.Request.Context(), token)
+	if err != nil {
+		h.sendInvitationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) AcceptInvitation(c *gin.Context) {
+	token := c.Param("token")
+
+	res, err := h.service.InvitationsService.AcceptInvitation(c.Request.Context(), token)
+	if err != nil {
+		h.sendInvitationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) sendInvitationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrPositionNotFound),
+		errors.Is(err, models.ErrUserNotFound),
+		errors.Is(err, models.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, sendResponse(-1, nil, err))
+	case errors.Is(err, models.ErrInvalidToken):
+		c.JSON(http.StatusUnauthorized, sendResponse(-1, nil, models.ErrInvalidToken))
+	case errors.Is(err, models.ErrInvitationExpired):
+		c.JSON(http.StatusGone, sendResponse(-1, nil, models.ErrInvitationExpired))
+	case errors.Is(err, models.ErrInvitationClosed):
+		c.JSON(http.StatusConflict, sendResponse(-1, nil, models.ErrInvitationClosed))
+	default:
+		h.sendSessionError(c, err)
+	}
+}
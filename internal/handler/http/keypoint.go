@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type KeyPoints struct {
+	KeyPoints []*models.KeyPoint `json:"key_points" binding:"dive"`
+}
+
+func (h *handler) GetKeyPoints(c *gin.Context) {
+	questionID := c.Param("id")
+
+	res, err := h.service.KeyPointsService.GetKeyPoints(c.Request.Context(), questionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) SetKeyPoints(c *gin.Context) {
+	questionID := c.Param("id")
+	req := &KeyPoints{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		h.logger.Errorf("Failed to parse request body when setting key points of question: %s\n", err.Error())
+		c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+		return
+	}
+
+	res, err := h.service.KeyPointsService.SetKeyPoints(questionID, req.KeyPoints)
+	if err != nil {
+		if errors.Is(err, models.ErrQuestionNotFound) {
+			c.JSON(http.StatusNotFound, sendResponse(-1, nil, models.ErrQuestionNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const progressHeartbeat = 15 * time.Second
+
+// StreamProgress pushes the progress of an interview as Server-Sent Events,
+// starting with its current state. The stream ends once the interview is
+// evaluated, failed or expired.
+func (h *handler) StreamProgress(c *gin.Context) {
+	publicID := c.Param("interview_public_id")
+
+	// subscribe first so that no change between the snapshot and the stream is lost
+	events, unsubscribe := h.progress.Subscribe(publicID)
+	defer unsubscribe()
+
+	current, err := h.service.InterviewsService.GetProgress(c.Request.Context(), publicID)
+	if err != nil {
+		if errors.Is(err, models.ErrInterviewNotFound) {
+			c.JSON(http.StatusNotFound, sendResponse(-1, nil, models.ErrInterviewNotFound))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent(current.Type, current)
+	if current.Final() {
+		return
+	}
+
+	heartbeat := time.NewTicker(progressHeartbeat)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return !event.Final()
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
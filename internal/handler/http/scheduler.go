@@ -0,0 +1,11 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) GetSchedulerRuns(c *gin.Context) {
+	c.JSON(http.StatusOK, sendResponse(0, h.scheduler.Runs(), nil))
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type AnswerVideo struct {
+	Video string `json:"video" binding:"required"`
+}
+
+// candidate lets the request through when it bears the session token of
+// the interview, issued when the invitation was accepted.
+func (h *handler) candidate(c *gin.Context) {
+	interviewID := c.Param("id")
+	if interviewID == "" {
+		interviewID = c.Param("interview_public_id")
+	}
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if err := h.service.InterviewsService.AuthorizeSession(interviewID, token); err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, sendResponse(-1, nil, models.ErrInvalidToken))
+		return
+	}
+	c.Next()
+}
+
+func (h *handler) StartInterview(c *gin.Context) {
+	interviewID := c.Param("id")
+
+	res, err := h.service.InterviewsService.StartInterview(c.Request.Context(), interviewID)
+	if err != nil {
+		h.sendSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) GetNextQuestion(c *gin.Context) {
+	interviewID := c.Param("interview_public_id")
+
+	res, err := h.service.InterviewsService.GetNextQuestion(c.Request.Context(), interviewID)
+	if err != nil {
+		h.sendSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) StartQuestion(c *gin.Context) {
+	interviewID := c.Param("id")
+	questionID := c.Param("question_id")
+
+	res, err := h.service.InterviewsService.StartQuestion(c.Request.Context(), interviewID, questionID)
+	if err != nil {
+		h.sendSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) SubmitAnswer(c *gin.Context) {
+	interviewID := c.Param("id")
+	questionID := c.Param("question_id")
+	req := &AnswerVideo{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		h.logger.Errorf("Failed to parse request body when submitting answer: %s\n", err.Error())
+		c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+		return
+	}
+
+	err := h.service.InterviewsService.SubmitAnswer(c.Request.Context(), interviewID, questionID, req.Video)
+	if err != nil {
+		h.sendSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, sendResponse(0, nil, nil))
+}
+
+func (h *handler) sendSessionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrInterviewNotFound):
+		c.JSON(http.StatusNotFound, sendResponse(-1, nil, models.ErrInterviewNotFound))
+	case errors.Is(err, models.ErrQuestionNotFound):
+		c.JSON(http.StatusNotFound, sendResponse(-1, nil, models.ErrQuestionNotFound))
+	case errors.Is(err, models.ErrDeadlineExceeded):
+		c.JSON(http.StatusForbidden, sendResponse(-1, nil, models.ErrDeadlineExceeded))
+	case errors.Is(err, models.ErrInterviewNotStarted),
+		errors.Is(err, models.ErrInterviewClosed),
+		errors.Is(err, models.ErrQuestionOutOfOrder),
+		errors.Is(err, models.ErrQuestionNotStarted),
+		errors.Is(err, models.ErrQuestionAnswered),
+		errors.Is(err, models.ErrInterviewSubmitted):
+		c.JSON(http.StatusConflict, sendResponse(-1, nil, err))
+	default:
+		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+	}
+}
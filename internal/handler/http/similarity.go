@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) GetPositionSimilarities(c *gin.Context) {
+	positionID := c.Param("id")
+
+	res, err := h.service.SimilaritiesService.GetPositionSimilarities(positionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *handler) SearchTranscripts(c *gin.Context) {
+	companyID := c.Param("company_id")
+	args, err := parseSearchArgs(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+		return
+	}
+
+	res, err := h.service.TranscriptsService.SearchTranscripts(companyID, c.Query("language"), args)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type WebhookEndpointRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required"`
+}
+
+func (h *handler) CreateWebhookEndpoint(c *gin.Context) {
+	companyID := c.Param("company_id")
+	req := &WebhookEndpointRequest{}
+	if err := c.ShouldBindWith(req, binding.JSON); err != nil {
+		h.logger.Errorf("Failed to parse request body when creating webhook endpoint: %s\n", err.Error())
+		c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+		return
+	}
+
+	res, err := h.service.WebhooksService.CreateWebhookEndpoint(companyID, req.URL, req.Events)
+	if err != nil {
+		h.sendWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, sendResponse(0, res, nil))
+}
+
+func (h *handler) GetCompanyWebhookEndpoints(c *gin.Context) {
+	companyID := c.Param("company_id")
+
+	res, err := h.service.WebhooksService.GetCompanyWebhookEndpoints(companyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) DeleteWebhookEndpoint(c *gin.Context) {
+	webhookID := c.Param("id")
+
+	if err := h.service.WebhooksService.DeleteWebhookEndpoint(webhookID); err != nil {
+		h.sendWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, nil, nil))
+}
+
+func (h *handler) GetWebhookDeliveries(c *gin.Context) {
+	webhookID := c.Param("id")
+	args, err := parseSearchArgs(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+		return
+	}
+
+	res, err := h.service.WebhooksService.GetWebhookDeliveries(webhookID, args)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) GetWebhookDelivery(c *gin.Context) {
+	deliveryID := c.Param("id")
+
+	res, err := h.service.WebhooksService.GetWebhookDelivery(deliveryID)
+	if err != nil {
+		h.sendWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sendResponse(0, res, nil))
+}
+
+func (h *handler) ReplayWebhookDelivery(c *gin.Context) {
+	deliveryID := c.Param("id")
+
+	res, err := h.service.WebhooksService.ReplayWebhookDelivery(deliveryID)
+	if err != nil {
+		h.sendWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, sendResponse(0, res, nil))
+}
+
+func (h *handler) sendWebhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, sendResponse(-1, nil, models.ErrInvalidInput))
+	case errors.Is(err, models.ErrCompanyNotFound),
+		errors.Is(err, models.ErrWebhookNotFound),
+		errors.Is(err, models.ErrDeliveryNotFound):
+		c.JSON(http.StatusNotFound, sendResponse(-1, nil, err))
+	default:
+		c.JSON(http.StatusInternalServerError, sendResponse(-1, nil, models.ErrInternalServer))
+	}
+}
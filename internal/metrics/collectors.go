@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type poolCollector struct {
+	pool         *pgxpool.Pool
+	acquired     *prometheus.Desc
+	idle         *prometheus.Desc
+	total        *prometheus.Desc
+	max          *prometheus.Desc
+	acquires     *prometheus.Desc
+	emptyAcquire *prometheus.Desc
+	waitDuration *prometheus.Desc
+}
+
+// NewPoolCollector reports the connections of pool, read when scraped.
+func NewPoolCollector(pool *pgxpool.Pool) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		pool:         pool,
+		acquired:     desc("acquired_connections", "Connections in use."),
+		idle:         desc("idle_connections", "Connections ready to be acquired."),
+		total:        desc("total_connections", "Connections open or being opened."),
+		max:          desc("max_connections", "Most connections the pool opens."),
+		acquires:     desc("acquires_total", "Connections acquired."),
+		emptyAcquire: desc("empty_acquires_total", "Acquires that waited for a connection."),
+		waitDuration: desc("acquire_wait_seconds_total", "Time spent acquiring connections."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.total
+	ch <- c.max
+	ch <- c.acquires
+	ch <- c.emptyAcquire
+	ch <- c.waitDuration
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquire, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
+
+type queueCollector struct {
+	stats    func() *models.AnalyzerQueue
+	inFlight *prometheus.Desc
+	queued   *prometheus.Desc
+	rejected *prometheus.Desc
+	timedOut *prometheus.Desc
+}
+
+// NewQueueCollector reports the calls to the analyzer in flight and waiting
+// in the queue, overall and by company, as returned by stats.
+func NewQueueCollector(stats func() *models.AnalyzerQueue) prometheus.Collector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "analyzer_queue", name), help, labels, nil)
+	}
+	return &queueCollector{
+		stats:    stats,
+		inFlight: desc("in_flight", "Calls to the analyzer in flight by company.", "company"),
+		queued:   desc("queued", "Calls waiting for the analyzer by company.", "company"),
+		rejected: desc("rejected_total", "Calls rejected for a full queue."),
+		timedOut: desc("timed_out_total", "Calls that waited longer than the queue timeout."),
+	}
+}
+
+func (c *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.inFlight
+	ch <- c.queued
+	ch <- c.rejected
+	ch <- c.timedOut
+}
+
+func (c *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats()
+	for company, q := range stats.Companies {
+		ch <- prometheus.MustNewConstMetric(c.inFlight, prometheus.GaugeValue, float64(q.InFlight), company)
+		ch <- prometheus.MustNewConstMetric(c.queued, prometheus.GaugeValue, float64(q.Queued), company)
+	}
+	ch <- prometheus.MustNewConstMetric(c.rejected, prometheus.CounterValue, float64(stats.Rejected))
+	ch <- prometheus.MustNewConstMetric(c.timedOut, prometheus.CounterValue, float64(stats.TimedOut))
+}
+
+type jobsCollector struct {
+	runs      func() []*models.JobRun
+	total     *prometheus.Desc
+	skipped   *prometheus.Desc
+	failures  *prometheus.Desc
+	processed *prometheus.Desc
+	backlog   *prometheus.Desc
+}
+
+// NewJobsCollector reports the runs of the scheduled jobs as returned by
+// runs. The items a job processed in its last run tell how deep its queue
+// was, as the jobs claim a batch of what is due on every run.
+func NewJobsCollector(runs func() []*models.JobRun) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "job", name), help, []string{"job"}, nil)
+	}
+	return &jobsCollector{
+		runs:      runs,
+		total:     desc("runs_total", "Runs of the job on this replica."),
+		skipped:   desc("skipped_total", "Runs skipped while another replica held the job."),
+		failures:  desc("failures_total", "Runs of the job that failed."),
+		processed: desc("processed_total", "Items the job processed."),
+		backlog:   desc("last_processed", "Items the last run of the job processed."),
+	}
+}
+
+func (c *jobsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.total
+	ch <- c.skipped
+	ch <- c.failures
+	ch <- c.processed
+	ch <- c.backlog
+}
+
+func (c *jobsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, run := range c.runs() {
+		ch <- prometheus.MustNewConstMetric(c.total, prometheus.CounterValue, float64(run.Runs), run.Name)
+		ch <- prometheus.MustNewConstMetric(c.skipped, prometheus.CounterValue, float64(run.Skipped), run.Name)
+		ch <- prometheus.MustNewConstMetric(c.failures, prometheus.CounterValue, float64(run.Failures), run.Name)
+		ch <- prometheus.MustNewConstMetric(c.processed, prometheus.CounterValue, float64(run.TotalProcessed), run.Name)
+		ch <- prometheus.MustNewConstMetric(c.backlog, prometheus.GaugeValue, float64(run.LastProcessed), run.Name)
+	}
+}
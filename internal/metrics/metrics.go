@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "interview"
+
+// Metrics holds the collectors of the service on a registry of its own.
+// All methods may be called on a nil *Metrics, which records nothing.
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	requestSize     *prometheus.HistogramVec
+	analyzerCalls   *prometheus.HistogramVec
+	scores          *prometheus.HistogramVec
+	questionScores  prometheus.Histogram
+}
+
+// New returns the metrics with the score distributions bucketed in tenths
+// of the range from minScore to maxScore, the bounds of the analyzer's
+// scores.
+func New(minScore, maxScore int) (*Metrics, error) {
+	if maxScore <= minScore {
+		return nil, fmt.Errorf("invalid score range %d to %d", minScore, maxScore)
+	}
+	scoreBuckets := prometheus.LinearBuckets(float64(minScore), float64(maxScore-minScore)/10, 11)
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time to answer HTTP requests by route, method and status.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300},
+		}, []string{"route", "method", "status"}),
+		requestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_size_bytes",
+			Help:      "Size of the bodies uploaded in HTTP requests by route.",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 8),
+		}, []string{"route", "method"}),
+		analyzerCalls: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "analyzer_call_duration_seconds",
+			Help:      "Time of the calls to the video analyzer by outcome.",
+			Buckets:   []float64{.5, 1, 5, 15, 30, 60, 120, 300, 600, 1200},
+		}, []string{"outcome"}),
+		scores: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "result_score",
+			Help:      "Overall scores of the saved interview results by source.",
+			Buckets:   scoreBuckets,
+		}, []string{"source"}),
+		questionScores: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "question_score",
+			Help:      "Scores of the analyzed answers.",
+			Buckets:   scoreBuckets,
+		}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.requestSize,
+		m.analyzerCalls,
+		m.scores,
+		m.questionScores,
+	)
+	return m, nil
+}
+
+// Register adds collectors to the registry of m.
+func (m *Metrics) Register(cs ...prometheus.Collector) error {
+	if m == nil {
+		return nil
+	}
+	for _, c := range cs {
+		if err := m.registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware counts and times the requests by their route template, so
+// that IDs in paths don't make a series each.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if m == nil {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		status := strconv.Itoa(c.Writer.Status())
+		m.requests.WithLabelValues(route, method, status).Inc()
+		m.requestDuration.WithLabelValues(route, method, status).Observe(time.Since(start).Seconds())
+		if c.Request.ContentLength > 0 {
+			m.requestSize.WithLabelValues(route, method).Observe(float64(c.Request.ContentLength))
+		}
+	}
+}
+
+// ObserveAnalyzerCall records a call to the analyzer that took d.
+func (m *Metrics) ObserveAnalyzerCall(outcome string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.analyzerCalls.WithLabelValues(outcome).Observe(d.Seconds())
+}
+
+// ObserveResult records the overall score of a saved result.
+func (m *Metrics) ObserveResult(source string, result *models.Result) {
+	if m == nil {
+		return
+	}
+	m.scores.WithLabelValues(source).Observe(float64(result.Score))
+}
+
+// ObserveQuestions records the scores of analyzed answers.
+func (m *Metrics) ObserveQuestions(questions []models.QuestionResult) {
+	if m == nil {
+		return
+	}
+	for _, q := range questions {
+		m.questionScores.Observe(float64(q.Score))
+	}
+}
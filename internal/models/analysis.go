@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+const (
+	QuestionAnalyzed       = "analyzed"
+	QuestionAnalysisFailed = "failed"
+)
+
+// QuestionAnalysis is the stored outcome of analyzing a single question.
+// Response keeps what the analyzer answered to a failed attempt.
+type QuestionAnalysis struct {
+	InterviewPublicID string          `json:"interview_public_id"`
+	QuestionPublicID  string          `json:"question_public_id"`
+	Status            string          `json:"status"`
+	Result            *QuestionResult `json:"result,omitempty"`
+	Error             string          `json:"error,omitempty"`
+	Response          string          `json:"response,omitempty"`
+	Attempts          int             `json:"attempts"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+}
+
+// CompanyPlan is the company an interview belongs to and its plan.
+type CompanyPlan struct {
+	CompanyPublicID string `json:"company_public_id"`
+	Plan            string `json:"plan"`
+}
+
+// AnalyzerQueue is a snapshot of the calls to the analyzer in flight and
+// waiting for a slot.
+type AnalyzerQueue struct {
+	InFlight    int                      `json:"in_flight"`
+	Queued      int                      `json:"queued"`
+	MaxInFlight int                      `json:"max_in_flight"`
+	MaxQueue    int                      `json:"max_queue"`
+	Rejected    int64                    `json:"rejected"`
+	TimedOut    int64                    `json:"timed_out"`
+	Companies   map[string]*CompanyQueue `json:"companies"`
+}
+
+type CompanyQueue struct {
+	InFlight int `json:"in_flight"`
+	Queued   int `json:"queued"`
+}
+
+const (
+	AnalysisRequestRunning   = "running"
+	AnalysisRequestCompleted = "completed"
+	AnalysisRequestFailed    = "failed"
+)
+
+// AnalysisRequest is a request to analyze an interview made with an
+// idempotency key, so that repeating it doesn't analyze the interview again.
+type AnalysisRequest struct {
+	InterviewPublicID string    `json:"interview_public_id"`
+	IdempotencyKey    string    `json:"idempotency_key"`
+	Status            string    `json:"status"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+const EmotionUnknown = "unknown"
+
+type EmotionAnalytics struct {
+	TimeShare   map[string]float64  `json:"time_share"`
+	Dominant    string              `json:"dominant_emotion"`
+	Transitions []EmotionTransition `json:"transitions"`
+	Volatility  float64             `json:"volatility"`
+	Heatmap     []EmotionBin        `json:"heatmap"`
+}
+
+type EmotionTransition struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int    `json:"count"`
+}
+
+type EmotionBin struct {
+	Start    float64            `json:"start"`
+	End      float64            `json:"end"`
+	Emotions map[string]float64 `json:"emotions"`
+}
@@ -12,4 +12,27 @@ var (
 	ErrCompanyNotFound     = errors.New("COMPANY_NOT_FOUND")
 	ErrInterviewNotFound   = errors.New("INTERVIEW_NOT_FOUND")
 	ErrQuestionNotFound    = errors.New("QUESTION_NOT_FOUND")
+	ErrInterviewNotStarted = errors.New("INTERVIEW_NOT_STARTED")
+	ErrInterviewClosed     = errors.New("INTERVIEW_CLOSED")
+	ErrDeadlineExceeded    = errors.New("DEADLINE_EXCEEDED")
+	ErrQuestionOutOfOrder  = errors.New("QUESTION_OUT_OF_ORDER")
+	ErrQuestionNotStarted  = errors.New("QUESTION_NOT_STARTED")
+	ErrQuestionAnswered    = errors.New("QUESTION_ALREADY_ANSWERED")
+	ErrPositionNotFound    = errors.New("POSITION_NOT_FOUND")
+	ErrInvitationNotFound  = errors.New("INVITATION_NOT_FOUND")
+	ErrInvalidToken        = errors.New("INVALID_TOKEN")
+	ErrInvitationExpired   = errors.New("INVITATION_EXPIRED")
+	ErrInvitationClosed    = errors.New("INVITATION_CLOSED")
+	ErrResultNotReady      = errors.New("RESULT_NOT_READY")
+	ErrWebhookNotFound     = errors.New("WEBHOOK_NOT_FOUND")
+	ErrDeliveryNotFound    = errors.New("DELIVERY_NOT_FOUND")
+	ErrAnalysisFailed      = errors.New("ANALYSIS_FAILED")
+	ErrAnalyzerBusy        = errors.New("ANALYZER_BUSY")
+	ErrAnalysisRateLimited = errors.New("ANALYSIS_RATE_LIMITED")
+	ErrDeadLetterNotFound  = errors.New("FAILED_ANALYSIS_NOT_FOUND")
+	ErrInvalidAnalysis     = errors.New("INVALID_ANALYSIS")
+	ErrRequestTimeout      = errors.New("REQUEST_TIMEOUT")
+	ErrAnalysisRunning     = errors.New("ANALYSIS_ALREADY_RUNNING")
+	ErrNotSubmitted        = errors.New("INTERVIEW_NOT_SUBMITTED")
+	ErrInterviewSubmitted  = errors.New("INTERVIEW_SUBMITTED")
 )
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	FailedAnalysisFailed    = "failed"
+	FailedAnalysisRetrying  = "retrying"
+	FailedAnalysisResolved  = "resolved"
+	FailedAnalysisDiscarded = "discarded"
+)
+
+// FailedAnalysis records an interview whose analysis failed, with what
+// the analyzer answered, until it is retried successfully or discarded.
+type FailedAnalysis struct {
+	PublicID          string    `json:"public_id"`
+	InterviewPublicID string    `json:"interview_public_id"`
+	Status            string    `json:"status"`
+	Error             string    `json:"error"`
+	StatusCode        *int      `json:"status_code,omitempty"`
+	ResponseBody      string    `json:"response_body,omitempty"`
+	Attempts          int       `json:"attempts"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
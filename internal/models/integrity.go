@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	IntegrityTabSwitch      = "tab_switch"
+	IntegrityFocusLoss      = "focus_loss"
+	IntegrityCopyPaste      = "copy_paste"
+	IntegrityFullscreenExit = "fullscreen_exit"
+	IntegrityMultipleFaces  = "multiple_faces"
+)
+
+const (
+	IntegritySourceClient   = "client"
+	IntegritySourceAnalyzer = "analyzer"
+)
+
+type IntegrityEvent struct {
+	Type             string                 `json:"type" binding:"required,oneof=tab_switch focus_loss copy_paste fullscreen_exit multiple_faces"`
+	QuestionPublicID string                 `json:"question_public_id"`
+	Source           string                 `json:"source" binding:"omitempty,oneof=client analyzer"`
+	OccurredAt       time.Time              `json:"occurred_at" binding:"required"`
+	Details          map[string]interface{} `json:"details,omitempty"`
+}
+
+type IntegrityCount struct {
+	QuestionPublicID string
+	Type             string
+	Count            int
+}
+
+type IntegritySummary struct {
+	Events     map[string]int            `json:"events"`
+	Questions  map[string]map[string]int `json:"questions"`
+	Total      int                       `json:"total"`
+	Score      float64                   `json:"score"`
+	Suspicious bool                      `json:"suspicious"`
+}
@@ -1,39 +1,84 @@
 package models
 
+import "time"
+
 type VideoRequest struct {
 	QuestionNumber int    `json:"questionNumber"`
 	VideoFile      []byte `json:"videoFile"`
 }
 
 type InterviewResults struct {
-	PublicID          string `json:"public_id"`
-	CandidatePublicID string `json:"candidate_public_id"`
-	Result            Result `json:"result"`
-	RawResult         []byte `json:"-"`
+	PublicID          string            `json:"public_id"`
+	CandidatePublicID string            `json:"candidate_public_id"`
+	Status            string            `json:"status"`
+	Result            Result            `json:"result"`
+	Integrity         *IntegritySummary `json:"integrity,omitempty"`
+	RawResult         []byte            `json:"-"`
 }
 
 type QuestionResult struct {
-	Question       string          `json:"question"`
-	PublicID       string          `json:"public_id"`
-	QuestionType   string          `json:"question_type"`
-	Evaluation     string          `json:"evaluation"`
-	Score          int             `json:"score"`
-	Answer         string          `json:"answer"`
-	Emotion        string          `json:"emotion"`
-	VideoLink      string          `json:"video_link"`
-	VideoPublicID  string          `json:"video_public_id"`
-	EmotionResults []EmotionResult `json:"emotion_results"`
+	Question       string            `json:"question"`
+	PublicID       string            `json:"public_id"`
+	QuestionType   string            `json:"question_type"`
+	Evaluation     string            `json:"evaluation"`
+	Score          int               `json:"score"`
+	AnalyzerScore  *int              `json:"analyzer_score,omitempty"`
+	Answer         string            `json:"answer"`
+	Language       string            `json:"language,omitempty"`
+	Emotion        string            `json:"emotion"`
+	RawEmotion     string            `json:"raw_emotion,omitempty"`
+	VideoLink      string            `json:"video_link"`
+	VideoPublicID  string            `json:"video_public_id"`
+	EmotionResults []EmotionResult   `json:"emotion_results"`
+	Duration       float64           `json:"duration,omitempty"`
+	Words          []WordTimestamp   `json:"words,omitempty"`
+	MultipleFaces  []float64         `json:"multiple_faces,omitempty"`
+	SpeechMetrics  *SpeechMetrics    `json:"speech_metrics,omitempty"`
+	Coverage       *KeywordCoverage  `json:"coverage,omitempty"`
+	Analytics      *EmotionAnalytics `json:"analytics,omitempty"`
 }
 
 type EmotionResult struct {
-	Emotion   string  `json:"emotion"`
-	ExactTime float64 `json:"exact_time"`
-	Duration  float64 `json:"duration"`
+	Emotion    string  `json:"emotion"`
+	RawEmotion string  `json:"raw_emotion,omitempty"`
+	Valence    float64 `json:"valence"`
+	Arousal    float64 `json:"arousal"`
+	ExactTime  float64 `json:"exact_time"`
+	Duration   float64 `json:"duration"`
 }
 
 type Result struct {
-	Questions []QuestionResult `json:"questions"`
-	Score     int              `json:"score"`
+	Questions       []QuestionResult  `json:"questions"`
+	Score           int               `json:"score"`
+	UnknownEmotions []string          `json:"unknown_emotions,omitempty"`
+	FailedQuestions []string          `json:"failed_questions,omitempty"`
+	Analytics       *EmotionAnalytics `json:"analytics,omitempty"`
+	Override        *ScoreOverride    `json:"override,omitempty"`
+}
+
+// ScoreOverride records a manual change of the overall score. OriginalScore
+// keeps the analyzer's score across repeated overrides.
+type ScoreOverride struct {
+	OriginalScore int       `json:"original_score"`
+	Reason        string    `json:"reason"`
+	OverriddenAt  time.Time `json:"overridden_at"`
+}
+
+// sources of a result version
+const (
+	ResultSourceAnalysis   = "analysis"
+	ResultSourceReanalysis = "reanalysis"
+	ResultSourceOverride   = "override"
+)
+
+// ResultVersion is a result an interview had. Versions are numbered from 1
+// in the order they were saved.
+type ResultVersion struct {
+	Version   int       `json:"version"`
+	Source    string    `json:"source"`
+	Score     int       `json:"score"`
+	Result    Result    `json:"result"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Question struct {
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	InvitationStatusSent    = "sent"
+	InvitationStatusOpened  = "opened"
+	InvitationStatusStarted = "started"
+	InvitationStatusExpired = "expired"
+	InvitationStatusRevoked = "revoked"
+)
+
+type Invitation struct {
+	PublicID          string     `json:"public_id"`
+	InterviewPublicID string     `json:"interview_public_id"`
+	CandidatePublicID string     `json:"candidate_public_id"`
+	PositionPublicID  string     `json:"position_public_id"`
+	Status            string     `json:"status"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	SentAt            time.Time  `json:"sent_at"`
+	OpenedAt          *time.Time `json:"opened_at"`
+	StartedAt         *time.Time `json:"started_at"`
+	RevokedAt         *time.Time `json:"revoked_at"`
+	ResendCount       int        `json:"resend_count"`
+	Token             string     `json:"token,omitempty"`
+	Link              string     `json:"link,omitempty"`
+	Nonce             string     `json:"-"`
+}
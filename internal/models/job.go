@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type JobRun struct {
+	Name           string     `json:"name"`
+	Interval       string     `json:"interval"`
+	Runs           int        `json:"runs"`
+	Skipped        int        `json:"skipped"`
+	Failures       int        `json:"failures"`
+	LastStartedAt  *time.Time `json:"last_started_at"`
+	LastDuration   string     `json:"last_duration"`
+	LastProcessed  int        `json:"last_processed"`
+	LastError      string     `json:"last_error,omitempty"`
+	TotalProcessed int        `json:"total_processed"`
+}
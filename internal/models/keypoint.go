@@ -0,0 +1,25 @@
+package models
+
+type KeyPoint struct {
+	PublicID string    `json:"public_id"`
+	Text     string    `json:"text" binding:"required"`
+	Keywords []Keyword `json:"keywords" binding:"required,min=1,dive"`
+	Weight   float64   `json:"weight" binding:"gte=0"`
+}
+
+type Keyword struct {
+	Term     string   `json:"term" binding:"required"`
+	Synonyms []string `json:"synonyms"`
+}
+
+type KeyPointMatch struct {
+	PublicID     string   `json:"public_id"`
+	Text         string   `json:"text"`
+	MatchedTerms []string `json:"matched_terms,omitempty"`
+}
+
+type KeywordCoverage struct {
+	Coverage float64         `json:"coverage"`
+	Covered  []KeyPointMatch `json:"covered"`
+	Missed   []KeyPointMatch `json:"missed"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	NotificationInvitation         = "invitation"
+	NotificationReminder           = "reminder"
+	NotificationInterviewSubmitted = "interview_submitted"
+	NotificationResultsReady       = "results_ready"
+)
+
+const (
+	DeliveryStatusSent   = "sent"
+	DeliveryStatusFailed = "failed"
+)
+
+type Notification struct {
+	Event             string
+	InterviewPublicID string
+	Link              string
+	ExpiresAt         *time.Time
+}
+
+type NotificationContext struct {
+	InterviewPublicID string
+	CandidateName     string
+	CandidateEmail    string
+	RecruiterName     string
+	RecruiterEmail    string
+	PositionName      string
+	CompanyName       string
+	CompanyLogo       string
+}
+
+type EmailDelivery struct {
+	InterviewPublicID string
+	Event             string
+	Recipient         string
+	Subject           string
+	Status            string
+	Error             string
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Interview status changes are recorded as "interview.<status>" events,
+// e.g. interview.submitted.
+const (
+	OutboxInterviewEventPrefix = "interview."
+	OutboxResultSaved          = "interview.result_saved"
+	OutboxVideoAdded           = "interview.video_added"
+	OutboxScoreOverridden      = "score.overridden"
+)
+
+// OutboxEvent is a domain event recorded in the transaction of the write
+// that caused it. AggregateID is the interview public id.
+type OutboxEvent struct {
+	ID          int64           `json:"id"`
+	AggregateID string          `json:"aggregate_id"`
+	Event       string          `json:"event"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	Handled     []string        `json:"handled"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
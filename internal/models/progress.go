@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+const ProgressChannel = "interview_progress"
+
+const (
+	ProgressStatus   = "status"
+	ProgressAnalysis = "analysis"
+	ProgressQuestion = "question"
+)
+
+const (
+	AnalysisStarted  = "started"
+	AnalysisAnalyzed = "analyzed"
+)
+
+// ProgressEvent is pushed to the clients following an interview. Status
+// events come from every status change of the interview, analysis and
+// question events from CreateInterviewResult. Question events are only
+// sent with per-question analysis, as a single call to the analyzer
+// reports on no question until all are done.
+type ProgressEvent struct {
+	InterviewPublicID string    `json:"interview_public_id"`
+	Type              string    `json:"type"`
+	Status            string    `json:"status,omitempty"`
+	Stage             string    `json:"stage,omitempty"`
+	QuestionPublicID  string    `json:"question_public_id,omitempty"`
+	Done              int       `json:"done,omitempty"`
+	Total             int       `json:"total,omitempty"`
+	At                time.Time `json:"at"`
+}
+
+// Final reports whether no further events follow for the interview.
+func (e *ProgressEvent) Final() bool {
+	if e.Type != ProgressStatus {
+		return false
+	}
+	switch e.Status {
+	case InterviewStatusEvaluated, InterviewStatusFailed, InterviewStatusExpired:
+		return true
+	}
+	return false
+}
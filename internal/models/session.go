@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+const (
+	InterviewStatusPending    = "pending"
+	InterviewStatusInProgress = "in_progress"
+	InterviewStatusSubmitted  = "submitted"
+	InterviewStatusEvaluated  = "evaluated"
+	InterviewStatusFailed     = "failed"
+	InterviewStatusExpired    = "expired"
+)
+
+// InterviewSession is the state of an interview as the candidate takes it.
+// Token authorizes taking it and is only returned when the invitation is
+// accepted.
+type InterviewSession struct {
+	PublicID          string     `json:"public_id"`
+	Status            string     `json:"status"`
+	StartedAt         *time.Time `json:"started_at"`
+	Deadline          *time.Time `json:"deadline"`
+	SubmittedAt       *time.Time `json:"submitted_at"`
+	QuestionsTotal    int        `json:"questions_total"`
+	QuestionsAnswered int        `json:"questions_answered"`
+	Token             string     `json:"token,omitempty"`
+}
+
+type SessionQuestion struct {
+	PublicID   string     `json:"public_id"`
+	Question   string     `json:"question"`
+	Number     int        `json:"number"`
+	TimeLimit  int        `json:"time_limit"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	Deadline   *time.Time `json:"deadline,omitempty"`
+	AnsweredAt *time.Time `json:"answered_at,omitempty"`
+}
@@ -0,0 +1,22 @@
+package models
+
+type WordTimestamp struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+type Pause struct {
+	Start    float64 `json:"start"`
+	Duration float64 `json:"duration"`
+}
+
+type SpeechMetrics struct {
+	WordCount          int     `json:"word_count"`
+	Duration           float64 `json:"duration"`
+	WordsPerMinute     float64 `json:"words_per_minute"`
+	FillerCount        int     `json:"filler_count"`
+	FillerRate         float64 `json:"filler_rate"`
+	VocabularyRichness float64 `json:"vocabulary_richness"`
+	LongPauses         []Pause `json:"long_pauses,omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+type Transcript struct {
+	InterviewPublicID string
+	QuestionPublicID  string
+	Language          string
+	Answer            string
+}
+
+// TranscriptMatch is an answer matching a search. Snippet is HTML: the
+// escaped answer with the matches wrapped in <mark>.
+type TranscriptMatch struct {
+	InterviewPublicID string  `json:"interview_public_id"`
+	QuestionPublicID  string  `json:"question_public_id"`
+	Question          string  `json:"question"`
+	CandidatePublicID string  `json:"candidate_public_id"`
+	Snippet           string  `json:"snippet"`
+	Rank              float32 `json:"rank"`
+}
+
+type TranscriptSearchResult struct {
+	Matches []*TranscriptMatch `json:"matches"`
+	Count   int                `json:"count"`
+}
+
+type AnswerSimilarity struct {
+	QuestionPublicID       string    `json:"question_public_id"`
+	Question               string    `json:"question"`
+	InterviewPublicID      string    `json:"interview_public_id"`
+	OtherInterviewPublicID string    `json:"other_interview_public_id"`
+	Score                  float64   `json:"score"`
+	Fragments              []string  `json:"fragments"`
+	CreatedAt              time.Time `json:"created_at"`
+}
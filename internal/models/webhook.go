@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	WebhookInterviewSubmitted = "interview.submitted"
+	WebhookInterviewEvaluated = "interview.evaluated"
+	WebhookInterviewFailed    = "interview.failed"
+	WebhookScoreOverridden    = "score.overridden"
+)
+
+// WebhookEvents lists the event types an endpoint may subscribe to.
+var WebhookEvents = []string{
+	WebhookInterviewSubmitted,
+	WebhookInterviewEvaluated,
+	WebhookInterviewFailed,
+	WebhookScoreOverridden,
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+type WebhookEndpoint struct {
+	PublicID        string    `json:"public_id"`
+	CompanyPublicID string    `json:"company_public_id"`
+	URL             string    `json:"url"`
+	Events          []string  `json:"events"`
+	Secret          string    `json:"secret,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// WebhookPayload is the body posted to the endpoints.
+type WebhookPayload struct {
+	Event      string            `json:"event"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Data       *WebhookInterview `json:"data"`
+}
+
+type WebhookInterview struct {
+	InterviewPublicID string         `json:"interview_public_id"`
+	CandidatePublicID string         `json:"candidate_public_id"`
+	Status            string         `json:"status"`
+	Score             *int           `json:"score,omitempty"`
+	Override          *ScoreOverride `json:"override,omitempty"`
+}
+
+type WebhookDelivery struct {
+	PublicID          string            `json:"public_id"`
+	EndpointPublicID  string            `json:"endpoint_public_id"`
+	Event             string            `json:"event"`
+	InterviewPublicID string            `json:"interview_public_id"`
+	Payload           json.RawMessage   `json:"payload"`
+	Status            string            `json:"status"`
+	Attempts          int               `json:"attempts"`
+	NextAttemptAt     *time.Time        `json:"next_attempt_at,omitempty"`
+	LastResponseCode  *int              `json:"last_response_code,omitempty"`
+	LastError         string            `json:"last_error,omitempty"`
+	DeliveredAt       *time.Time        `json:"delivered_at,omitempty"`
+	ReplayOf          *string           `json:"replay_of,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+	Log               []*WebhookAttempt `json:"attempts_log,omitempty"`
+	URL               string            `json:"-"`
+	Secret            string            `json:"-"`
+}
+
+type WebhookAttempt struct {
+	Attempt      int       `json:"attempt"`
+	ResponseCode *int      `json:"response_code,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	DurationMs   int64     `json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,49 @@
+package notification
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"go.uber.org/zap"
+)
+
+// logNotifier is the development stand-in for SMTP. It logs every message
+// and, when an output directory is configured, writes it there as .eml.
+type logNotifier struct {
+	dir    string
+	logger *zap.SugaredLogger
+}
+
+func NewLogNotifier(cfg *config.SMTP, logger *zap.SugaredLogger) Notifier {
+	return &logNotifier{
+		dir:    cfg.OutputDir,
+		logger: logger,
+	}
+}
+
+func (n *logNotifier) Send(msg *Message) error {
+	n.logger.Infof("email to %s: %s", msg.To, msg.Subject)
+	if n.dir == "" {
+		return nil
+	}
+	from, err := parseAddress(msg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %v", msg.From, err)
+	}
+	to, err := parseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %v", msg.To, err)
+	}
+	if err := os.MkdirAll(n.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %v", err)
+	}
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), strings.NewReplacer("@", "_at_", "/", "_").Replace(to.Address))
+	if err := os.WriteFile(filepath.Join(n.dir, name), encode(from, to, msg), 0644); err != nil {
+		return fmt.Errorf("failed to write email: %v", err)
+	}
+	return nil
+}
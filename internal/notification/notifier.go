@@ -0,0 +1,35 @@
+package notification
+
+import (
+	"fmt"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"go.uber.org/zap"
+)
+
+const (
+	DriverSMTP = "smtp"
+	DriverLog  = "log"
+)
+
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	HTML    string
+}
+
+type Notifier interface {
+	Send(msg *Message) error
+}
+
+func New(cfg *config.SMTP, logger *zap.SugaredLogger) (Notifier, error) {
+	switch cfg.Driver {
+	case DriverSMTP:
+		return NewSMTPNotifier(cfg), nil
+	case DriverLog, "":
+		return NewLogNotifier(cfg, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown smtp driver %q", cfg.Driver)
+	}
+}
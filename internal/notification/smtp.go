@@ -0,0 +1,65 @@
+package notification
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+)
+
+type smtpNotifier struct {
+	cfg *config.SMTP
+}
+
+func NewSMTPNotifier(cfg *config.SMTP) Notifier {
+	return &smtpNotifier{cfg: cfg}
+}
+
+func (n *smtpNotifier) Send(msg *Message) error {
+	from, err := parseAddress(msg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %v", msg.From, err)
+	}
+	to, err := parseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %v", msg.To, err)
+	}
+
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+	addr := n.cfg.Host + ":" + strconv.Itoa(n.cfg.Port)
+	if err := smtp.SendMail(addr, auth, from.Address, []string{to.Address}, encode(from, to, msg)); err != nil {
+		return fmt.Errorf("failed to send email to %s: %v", to.Address, err)
+	}
+	return nil
+}
+
+// parseAddress parses a single address, rejecting line breaks that would
+// let it add headers of its own.
+func parseAddress(address string) (*mail.Address, error) {
+	if strings.ContainsAny(address, "\r\n") {
+		return nil, errors.New("address contains a line break")
+	}
+	return mail.ParseAddress(address)
+}
+
+// encode writes the message with the addresses formatted by net/mail and
+// the subject as an RFC 2047 encoded word.
+func encode(from, to *mail.Address, msg *Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.HTML)
+	return b.Bytes()
+}
@@ -0,0 +1,66 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var events = []string{
+	models.NotificationInvitation,
+	models.NotificationReminder,
+	models.NotificationInterviewSubmitted,
+	models.NotificationResultsReady,
+}
+
+type TemplateData struct {
+	CompanyName       string
+	CompanyLogo       string
+	CandidateName     string
+	RecruiterName     string
+	PositionName      string
+	InterviewPublicID string
+	Link              string
+	ExpiresAt         *time.Time
+}
+
+type Templates struct {
+	byEvent map[string]*template.Template
+}
+
+func NewTemplates() (*Templates, error) {
+	t := &Templates{byEvent: make(map[string]*template.Template)}
+	for _, event := range events {
+		tmpl, err := template.ParseFS(templateFS, "templates/layout.html", "templates/"+event+".html")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s template: %v", event, err)
+		}
+		t.byEvent[event] = tmpl
+	}
+	return t, nil
+}
+
+// Render returns the subject and the HTML body of the email for event.
+func (t *Templates) Render(event string, data *TemplateData) (string, string, error) {
+	tmpl, ok := t.byEvent[event]
+	if !ok {
+		return "", "", fmt.Errorf("unknown notification event %q", event)
+	}
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.ExecuteTemplate(&body, "layout", data); err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(html.UnescapeString(subject.String())), body.String(), nil
+}
@@ -0,0 +1,102 @@
+package outbox
+
+import (
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Handler processes one event. Events it does not care about are ignored
+// by returning nil.
+type Handler func(event *models.OutboxEvent) error
+
+type subscriber struct {
+	name    string
+	handler Handler
+}
+
+// Relay hands the events recorded in the outbox to the subscribers. Events
+// are delivered at least once and, per interview, in the order they were
+// recorded: a failing event holds back the later events of its interview
+// until it succeeds or MaxAttempts is reached. A subscriber that already
+// handled an event is not called again when the event is retried.
+type Relay struct {
+	cfg         *config.Outbox
+	outboxRepo  repository.OutboxRepository
+	logger      *zap.SugaredLogger
+	subscribers []*subscriber
+}
+
+func NewRelay(cfg *config.Outbox, outboxRepo repository.OutboxRepository, logger *zap.SugaredLogger) *Relay {
+	return &Relay{
+		cfg:        cfg,
+		outboxRepo: outboxRepo,
+		logger:     logger,
+	}
+}
+
+// Subscribe registers handler under name. Subscribers must be registered
+// before the relay runs.
+func (r *Relay) Subscribe(name string, handler Handler) {
+	r.subscribers = append(r.subscribers, &subscriber{name: name, handler: handler})
+}
+
+// Relay publishes the pending events and reports how many were published.
+// Only one relay may run at a time, which the scheduler guarantees.
+func (r *Relay) Relay() (int, error) {
+	events, err := r.outboxRepo.GetPendingEvents(r.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	blocked := make(map[string]bool)
+	for _, event := range events {
+		if blocked[event.AggregateID] {
+			continue
+		}
+		ok, err := r.publish(event)
+		if err != nil {
+			return published, err
+		}
+		if !ok {
+			blocked[event.AggregateID] = true
+			continue
+		}
+		published++
+	}
+	return published, nil
+}
+
+// publish runs the subscribers that have not handled event yet. It reports
+// false when one of them failed and the event has to be retried.
+func (r *Relay) publish(event *models.OutboxEvent) (bool, error) {
+	for _, s := range r.subscribers {
+		if handled(event, s.name) {
+			continue
+		}
+		if err := s.handler(event); err != nil {
+			if event.Attempts+1 >= r.cfg.MaxAttempts {
+				r.logger.Errorf("outbox event %d (%s of %s) dropped by %s after %d attempts: %v",
+					event.ID, event.Event, event.AggregateID, s.name, event.Attempts+1, err)
+				return true, r.outboxRepo.MarkEventPublished(event.ID, s.name+": "+err.Error())
+			}
+			r.logger.Warnf("outbox event %d (%s of %s) failed in %s: %v", event.ID, event.Event, event.AggregateID, s.name, err)
+			return false, r.outboxRepo.MarkEventFailed(event.ID, s.name+": "+err.Error())
+		}
+		if err := r.outboxRepo.MarkEventHandled(event.ID, s.name); err != nil {
+			return false, err
+		}
+	}
+	return true, r.outboxRepo.MarkEventPublished(event.ID, "")
+}
+
+func handled(event *models.OutboxEvent, subscriber string) bool {
+	for _, name := range event.Handled {
+		if name == subscriber {
+			return true
+		}
+	}
+	return false
+}
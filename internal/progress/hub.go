@@ -0,0 +1,115 @@
+package progress
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+// subscriber buffer; events for a subscriber that falls this far behind
+// are dropped
+const bufferSize = 32
+
+// Hub fans the progress events received through Postgres LISTEN out to the
+// subscribers of this replica.
+type Hub struct {
+	progressRepo repository.ProgressRepository
+	logger       *zap.SugaredLogger
+	wg           sync.WaitGroup
+
+	mu     sync.Mutex
+	subs   map[string]map[chan *models.ProgressEvent]struct{}
+	closed bool
+}
+
+func NewHub(progressRepo repository.ProgressRepository, logger *zap.SugaredLogger) *Hub {
+	return &Hub{
+		progressRepo: progressRepo,
+		logger:       logger,
+		subs:         make(map[string]map[chan *models.ProgressEvent]struct{}),
+	}
+}
+
+// Start listens in the background until ctx is cancelled, reconnecting
+// after failures. Cancelling ctx closes every subscription.
+func (h *Hub) Start(ctx context.Context) {
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		defer h.closeAll()
+		for {
+			err := h.progressRepo.ListenProgress(ctx, h.dispatch)
+			if ctx.Err() != nil {
+				return
+			}
+			h.logger.Errorf("progress listener stopped, reconnecting: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+		}
+	}()
+}
+
+func (h *Hub) Wait() {
+	h.wg.Wait()
+}
+
+// Subscribe returns the events of the interview and a function ending the
+// subscription. The channel is closed when the hub stops.
+func (h *Hub) Subscribe(interviewPublicID string) (<-chan *models.ProgressEvent, func()) {
+	ch := make(chan *models.ProgressEvent, bufferSize)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if h.subs[interviewPublicID] == nil {
+		h.subs[interviewPublicID] = make(map[chan *models.ProgressEvent]struct{})
+	}
+	h.subs[interviewPublicID][ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[interviewPublicID][ch]; !ok {
+			return
+		}
+		delete(h.subs[interviewPublicID], ch)
+		if len(h.subs[interviewPublicID]) == 0 {
+			delete(h.subs, interviewPublicID)
+		}
+		close(ch)
+	}
+}
+
+func (h *Hub) dispatch(event *models.ProgressEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[event.InterviewPublicID] {
+		select {
+		case ch <- event:
+		default:
+			h.logger.Warnf("progress subscriber of interview %s is too slow, event dropped", event.InterviewPublicID)
+		}
+	}
+}
+
+func (h *Hub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subs {
+		for ch := range subs {
+			close(ch)
+		}
+	}
+	h.subs = make(map[string]map[chan *models.ProgressEvent]struct{})
+	h.closed = true
+}
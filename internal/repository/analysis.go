@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+type analysisRepository struct {
+	db     *pgxpool.Pool
+	cfg    *config.DBConf
+	logger *zap.SugaredLogger
+}
+
+func NewAnalysisRepository(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.SugaredLogger) AnalysisRepository {
+	return &analysisRepository{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// SaveQuestionAnalysis stores the latest outcome of analyzing a question.
+// A failed attempt keeps the last successful result.
+func (r *analysisRepository) SaveQuestionAnalysis(ctx context.Context, analysis *models.QuestionAnalysis) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		INSERT INTO question_results (interview_public_id, question_public_id, status, result, error, response)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''))
+		ON CONFLICT (interview_public_id, question_public_id)
+		DO UPDATE SET status = EXCLUDED.status, result = COALESCE(EXCLUDED.result, question_results.result),
+			error = EXCLUDED.error, response = EXCLUDED.response, attempts = question_results.attempts + 1, updated_at = NOW();
+	`
+
+	var result []byte
+	if analysis.Result != nil {
+		var err error
+		if result, err = json.Marshal(analysis.Result); err != nil {
+			r.logger.Errorf("Failed to marshal question result to JSON: %v", err)
+			return err
+		}
+	}
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, analysis.InterviewPublicID, analysis.QuestionPublicID, analysis.Status, result, analysis.Error, analysis.Response)
+	if err != nil {
+		r.logger.Errorf("Error occurred while saving question result: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *analysisRepository) GetQuestionAnalyses(ctx context.Context, interviewPublicID string) ([]*models.QuestionAnalysis, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT interview_public_id, question_public_id, status, result, COALESCE(error, ''), COALESCE(response, ''), attempts, updated_at
+		FROM question_results
+		WHERE interview_public_id = $1
+		ORDER BY id;
+	`
+
+	result := make([]*models.QuestionAnalysis, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, query, interviewPublicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while retrieving question results: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		a := &models.QuestionAnalysis{}
+		var resultBytes []byte
+		err = rows.Scan(&a.InterviewPublicID, &a.QuestionPublicID, &a.Status, &resultBytes, &a.Error, &a.Response, &a.Attempts, &a.UpdatedAt)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		if resultBytes != nil {
+			a.Result = &models.QuestionResult{}
+			if err = json.Unmarshal(resultBytes, a.Result); err != nil {
+				r.logger.Errorf("Error occurred while unmarshll: %v", err)
+				return nil, err
+			}
+		}
+		result = append(result, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+const selectFailedAnalyses = `
+	SELECT f.public_id, f.interview_public_id, f.status, f.error, f.status_code, f.attempts, f.created_at, f.updated_at
+	FROM failed_analyses AS f
+`
+
+type deadLetterRepository struct {
+	db     *pgxpool.Pool
+	cfg    *config.DBConf
+	logger *zap.SugaredLogger
+}
+
+func NewDeadLetterRepository(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.SugaredLogger) DeadLetterRepository {
+	return &deadLetterRepository{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// RecordFailedAnalysis stores the failure of the interview's analysis. A
+// failure of an interview already recorded replaces the error and counts
+// as another attempt.
+func (r *deadLetterRepository) RecordFailedAnalysis(ctx context.Context, failure *models.FailedAnalysis) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		INSERT INTO failed_analyses (interview_public_id, error, status_code, response_body)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		ON CONFLICT (interview_public_id)
+		DO UPDATE SET status = 'failed', error = EXCLUDED.error, status_code = EXCLUDED.status_code,
+			response_body = EXCLUDED.response_body, attempts = failed_analyses.attempts + 1, updated_at = NOW()
+		RETURNING public_id, status, attempts, created_at, updated_at;
+	`
+
+	err := conn(ctx, r.db).QueryRow(ctx, query, failure.InterviewPublicID, failure.Error, failure.StatusCode, failure.ResponseBody).
+		Scan(&failure.PublicID, &failure.Status, &failure.Attempts, &failure.CreatedAt, &failure.UpdatedAt)
+	if err != nil {
+		r.logger.Errorf("Error occurred while recording failed analysis: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ResolveFailedAnalysis marks the failure of the interview's analysis as
+// resolved once the interview was analyzed.
+func (r *deadLetterRepository) ResolveFailedAnalysis(ctx context.Context, interviewPublicID string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		UPDATE failed_analyses SET status = 'resolved', updated_at = NOW()
+		WHERE interview_public_id = $1 AND status IN ('failed', 'retrying');
+	`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, interviewPublicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while resolving failed analysis: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetFailedAnalyses lists the failures with the given status, or all of
+// them if status is empty, the most recent first.
+func (r *deadLetterRepository) GetFailedAnalyses(status string, args *models.SearchArgs) ([]*models.FailedAnalysis, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := selectFailedAnalyses + `
+		WHERE $1 = '' OR f.status = $1
+		ORDER BY f.updated_at DESC
+		LIMIT $2 OFFSET $3;
+	`
+
+	result := make([]*models.FailedAnalysis, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, query, status, args.PageSize, (args.PageNum-1)*args.PageSize)
+	if err != nil {
+		r.logger.Errorf("Error occurred while retrieving failed analyses: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		f, err := scanFailedAnalysis(rows)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result = append(result, f)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetFailedAnalysis returns the failure together with the analyzer's
+// response body.
+func (r *deadLetterRepository) GetFailedAnalysis(publicID string) (*models.FailedAnalysis, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT f.public_id, f.interview_public_id, f.status, f.error, f.status_code, f.attempts, f.created_at, f.updated_at,
+			COALESCE(f.response_body, '')
+		FROM failed_analyses AS f
+		WHERE f.public_id = $1;
+	`
+
+	f := &models.FailedAnalysis{}
+	err := conn(ctx, r.db).QueryRow(ctx, query, publicID).Scan(&f.PublicID, &f.InterviewPublicID, &f.Status, &f.Error, &f.StatusCode,
+		&f.Attempts, &f.CreatedAt, &f.UpdatedAt, &f.ResponseBody)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrDeadLetterNotFound
+		}
+		r.logger.Errorf("Error occurred while retrieving failed analysis: %v", err)
+		return nil, err
+	}
+	return f, nil
+}
+
+// ClaimFailedAnalyses marks the failed ones among publicIDs as being
+// retried and returns them. Failures already being retried, resolved or
+// discarded are left out.
+func (r *deadLetterRepository) ClaimFailedAnalyses(publicIDs []string) ([]*models.FailedAnalysis, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		UPDATE failed_analyses AS f SET status = 'retrying', updated_at = NOW()
+		WHERE f.public_id = ANY($1::uuid[]) AND f.status = 'failed'
+		RETURNING f.public_id, f.interview_public_id, f.status, f.error, f.status_code, f.attempts, f.created_at, f.updated_at;
+	`
+
+	result := make([]*models.FailedAnalysis, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, query, publicIDs)
+	if err != nil {
+		r.logger.Errorf("Error occurred while claiming failed analyses: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		f, err := scanFailedAnalysis(rows)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result = append(result, f)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ReleaseFailedAnalysis puts the failure of the interview's analysis back
+// to failed if it is still being retried, counting the retry as another
+// attempt. A retry recorded, resolved or discarded meanwhile is left alone.
+func (r *deadLetterRepository) ReleaseFailedAnalysis(ctx context.Context, failure *models.FailedAnalysis) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		UPDATE failed_analyses SET status = 'failed', error = $2, status_code = $3, response_body = NULLIF($4, ''),
+			attempts = attempts + 1, updated_at = NOW()
+		WHERE interview_public_id = $1 AND status = 'retrying';
+	`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, failure.InterviewPublicID, failure.Error, failure.StatusCode, failure.ResponseBody)
+	if err != nil {
+		r.logger.Errorf("Error occurred while releasing failed analysis: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DiscardFailedAnalyses gives up on the failures among publicIDs that are
+// not resolved and reports how many were discarded.
+func (r *deadLetterRepository) DiscardFailedAnalyses(publicIDs []string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		UPDATE failed_analyses SET status = 'discarded', updated_at = NOW()
+		WHERE public_id = ANY($1::uuid[]) AND status IN ('failed', 'retrying');
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, publicIDs)
+	if err != nil {
+		r.logger.Errorf("Error occurred while discarding failed analyses: %v", err)
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func scanFailedAnalysis(row pgx.Row) (*models.FailedAnalysis, error) {
+	f := &models.FailedAnalysis{}
+	err := row.Scan(&f.PublicID, &f.InterviewPublicID, &f.Status, &f.Error, &f.StatusCode, &f.Attempts, &f.CreatedAt, &f.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+type idempotencyRepository struct {
+	db     *pgxpool.Pool
+	cfg    *config.DBConf
+	logger *zap.SugaredLogger
+}
+
+func NewIdempotencyRepository(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.SugaredLogger) IdempotencyRepository {
+	return &idempotencyRepository{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// GetAnalysisRequest returns the request to analyze the interview made
+// with key, or nil when there is none.
+func (r *idempotencyRepository) GetAnalysisRequest(ctx context.Context, interviewPublicID, key string) (*models.AnalysisRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT interview_public_id, idempotency_key, status, created_at, updated_at
+		FROM analysis_requests
+		WHERE interview_public_id = $1 AND idempotency_key = $2;
+	`
+	request := &models.AnalysisRequest{}
+	err := conn(ctx, r.db).QueryRow(ctx, query, interviewPublicID, key).Scan(&request.InterviewPublicID,
+		&request.IdempotencyKey, &request.Status, &request.CreatedAt, &request.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		r.logger.Errorf("Error occurred while retrieving analysis request: %v", err)
+		return nil, err
+	}
+	return request, nil
+}
+
+// SetAnalysisRequest records the request to analyze the interview made
+// with key, or updates its status.
+func (r *idempotencyRepository) SetAnalysisRequest(ctx context.Context, interviewPublicID, key, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		INSERT INTO analysis_requests (interview_public_id, idempotency_key, status)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (interview_public_id, idempotency_key)
+		DO UPDATE SET status = EXCLUDED.status, updated_at = NOW();
+	`
+	if _, err := conn(ctx, r.db).Exec(ctx, query, interviewPublicID, key, status); err != nil {
+		r.logger.Errorf("Error occurred while saving analysis request: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+type integrityRepository struct {
+	db     *pgxpool.Pool
+	cfg    *config.DBConf
+	logger *zap.SugaredLogger
+}
+
+func NewIntegrityRepository(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.SugaredLogger) IntegrityRepository {
+	return &integrityRepository{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *integrityRepository) AddIntegrityEvents(interviewPublicID string, events []*models.IntegrityEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Error occurred while starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// the row is kept from being submitted until the events are added
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM interviews WHERE public_id = $1 FOR SHARE`, interviewPublicID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ErrInterviewNotFound
+		}
+		r.logger.Errorf("Error occurred while checking interview: %v", err)
+		return err
+	}
+	switch status {
+	case models.InterviewStatusInProgress:
+	case models.InterviewStatusPending:
+		return models.ErrInterviewNotStarted
+	default:
+		return models.ErrInterviewClosed
+	}
+
+	query := `
+		INSERT INTO integrity_events (interview_public_id, question_public_id, type, source, occurred_at, details)
+		VALUES ($1, NULLIF($2, '')::uuid, $3, $4, $5, $6);
+	`
+	for _, e := range events {
+		details, err := json.Marshal(e.Details)
+		if err != nil {
+			r.logger.Errorf("Failed to marshal event details to JSON: %v", err)
+			return err
+		}
+		_, err = tx.Exec(ctx, query, interviewPublicID, e.QuestionPublicID, e.Type, e.Source, e.OccurredAt, details)
+		if err != nil {
+			r.logger.Errorf("Error occurred while adding integrity event: %v", err)
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Error occurred while committing integrity events: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ReplaceAnalyzerEvents replaces the events the analyzer reported for the
+// questions, so that analyzing a question again doesn't count them twice.
+func (r *integrityRepository) ReplaceAnalyzerEvents(ctx context.Context, interviewPublicID string, questionPublicIDs []string, events []*models.IntegrityEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Error occurred while starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM integrity_events
+		WHERE interview_public_id = $1 AND question_public_id = ANY($2::uuid[]) AND source = $3;
+	`, interviewPublicID, questionPublicIDs, models.IntegritySourceAnalyzer)
+	if err != nil {
+		r.logger.Errorf("Error occurred while deleting integrity events: %v", err)
+		return err
+	}
+
+	query := `
+		INSERT INTO integrity_events (interview_public_id, question_public_id, type, source, occurred_at, details)
+		VALUES ($1, NULLIF($2, '')::uuid, $3, $4, $5, $6);
+	`
+	for _, e := range events {
+		details, err := json.Marshal(e.Details)
+		if err != nil {
+			r.logger.Errorf("Failed to marshal event details to JSON: %v", err)
+			return err
+		}
+		_, err = tx.Exec(ctx, query, interviewPublicID, e.QuestionPublicID, e.Type, e.Source, e.OccurredAt, details)
+		if err != nil {
+			r.logger.Errorf("Error occurred while adding integrity event: %v", err)
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Error occurred while committing integrity events: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *integrityRepository) GetIntegrityCounts(ctx context.Context, interviewPublicIDs ...string) (map[string][]*models.IntegrityCount, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT interview_public_id, COALESCE(question_public_id::text, ''), type, COUNT(*)
+		FROM integrity_events
+		WHERE interview_public_id = ANY($1::uuid[])
+		GROUP BY interview_public_id, question_public_id, type;
+	`
+
+	result := make(map[string][]*models.IntegrityCount)
+	rows, err := conn(ctx, r.db).Query(ctx, query, interviewPublicIDs)
+	if err != nil {
+		r.logger.Errorf("Error occurred while retrieving integrity events: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var interviewPublicID string
+		count := &models.IntegrityCount{}
+		err = rows.Scan(&interviewPublicID, &count.QuestionPublicID, &count.Type, &count.Count)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result[interviewPublicID] = append(result[interviewPublicID], count)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/Zhiyenbek/sp-interview-main-service/config"
 	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"go.uber.org/zap"
 )
@@ -25,8 +28,8 @@ func NewInterviewRepository(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.Su
 	}
 }
 
-func (r *interviewRepository) GetInterviewByPublicID(publicID string) (*models.InterviewResults, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+func (r *interviewRepository) GetInterviewByPublicID(ctx context.Context, publicID string) (*models.InterviewResults, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
 	defer cancel()
 
 	query := `
@@ -40,7 +43,7 @@ func (r *interviewRepository) GetInterviewByPublicID(publicID string) (*models.I
 	`
 
 	result := models.InterviewResults{}
-	rows, err := r.db.Query(ctx, query, publicID)
+	rows, err := conn(ctx, r.db).Query(ctx, query, publicID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, models.ErrInterviewNotFound
@@ -78,15 +81,18 @@ func (r *interviewRepository) GetInterviewByPublicID(publicID string) (*models.I
 	JOIN interviews i ON i.id = ui.interview_id
 	WHERE i.public_id = $1 
 	GROUP BY c.public_id`
-	err = r.db.QueryRow(ctx, query, publicID).Scan(&result.CandidatePublicID)
+	err = conn(ctx, r.db).QueryRow(ctx, query, publicID).Scan(&result.CandidatePublicID)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrInterviewNotFound
+		}
 		r.logger.Errorf("Error occurred while getting candidate public id: %v", err)
 		return nil, err
 	}
 	return &result, nil
 }
-func (r *interviewRepository) PutInterview(interview *models.InterviewResults) error {
-	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+func (r *interviewRepository) PutInterview(ctx context.Context, interview *models.InterviewResults) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
 	defer cancel()
 
 	query := `
@@ -102,18 +108,42 @@ func (r *interviewRepository) PutInterview(interview *models.InterviewResults) e
 		return err
 	}
 
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Error occurred while starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
 	// Execute the update query
-	_, err = r.db.Exec(ctx, query, jsonData, interview.PublicID)
+	_, err = tx.Exec(ctx, query, jsonData, interview.PublicID)
 	if err != nil {
 		r.logger.Errorf("Error occurred while updating interview results: %v", err)
 		return err
 	}
 
+	event := models.OutboxResultSaved
+	if interview.Result.Override != nil {
+		event = models.OutboxScoreOverridden
+	}
+	err = addOutboxEvent(ctx, tx, interview.PublicID, event, map[string]interface{}{
+		"score":    interview.Result.Score,
+		"override": interview.Result.Override,
+	})
+	if err != nil {
+		r.logger.Errorf("Error occurred while recording outbox event: %v", err)
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Error occurred while committing interview results: %v", err)
+		return err
+	}
 	return nil
 }
 
-func (r *interviewRepository) AddVideoToQuestion(questionPublicID, interviewPublicID, video string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+func (r *interviewRepository) AddVideoToQuestion(ctx context.Context, questionPublicID, interviewPublicID, video string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
 	defer cancel()
 
 	query := `
@@ -122,29 +152,45 @@ func (r *interviewRepository) AddVideoToQuestion(questionPublicID, interviewPubl
 	RETURNING id;
 `
 
-	_, err := r.db.Exec(ctx, query, interviewPublicID, questionPublicID, video)
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Error occurred while starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query, interviewPublicID, questionPublicID, video)
 	if err != nil {
 		r.logger.Errorf("Error occurred while adding video to question: %v", err)
 		return err
 	}
 
+	if err = addVideoEvent(ctx, tx, interviewPublicID, questionPublicID, video); err != nil {
+		r.logger.Errorf("Error occurred while recording outbox event: %v", err)
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Error occurred while committing video: %v", err)
+		return err
+	}
 	return nil
 }
 
-func (r *interviewRepository) GetAllInterviews() ([]*models.InterviewResults, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+func (r *interviewRepository) GetAllInterviews(ctx context.Context) ([]*models.InterviewResults, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
 	defer cancel()
 
 	query := `
-		SELECT i.public_id, c.public_id, i.results
+		SELECT i.public_id, c.public_id, i.status, i.results
 		FROM interviews AS i
 		LEFT JOIN user_interviews ui ON ui.interview_id = i.id
 		LEFT JOIN candidates c ON c.id = ui.candidate_id
-		GROUP BY i.public_id, c.public_id, i.results
+		GROUP BY i.public_id, c.public_id, i.status, i.results
 	`
 
 	result := make([]*models.InterviewResults, 0)
-	rows, err := r.db.Query(ctx, query)
+	rows, err := conn(ctx, r.db).Query(ctx, query)
 	if err != nil {
 		r.logger.Errorf("Error occurred while retrieving interview result: %v", err)
 		return nil, err
@@ -155,15 +201,17 @@ func (r *interviewRepository) GetAllInterviews() ([]*models.InterviewResults, er
 		var err error
 		interview := &models.InterviewResults{}
 		var resultBytes []byte
-		err = rows.Scan(&interview.PublicID, &interview.CandidatePublicID, &resultBytes)
+		err = rows.Scan(&interview.PublicID, &interview.CandidatePublicID, &interview.Status, &resultBytes)
 		if err != nil {
 			r.logger.Errorf("Error occurred while scanning rows: %v", err)
 			return nil, err
 		}
-		err = json.Unmarshal(resultBytes, &interview.Result)
-		if err != nil {
-			r.logger.Errorf("Error occurred while unmarshll: %v", err)
-			return nil, err
+		if resultBytes != nil {
+			err = json.Unmarshal(resultBytes, &interview.Result)
+			if err != nil {
+				r.logger.Errorf("Error occurred while unmarshll: %v", err)
+				return nil, err
+			}
 		}
 		result = append(result, interview)
 	}
@@ -176,30 +224,437 @@ func (r *interviewRepository) GetAllInterviews() ([]*models.InterviewResults, er
 	return result, nil
 }
 
-func (r *interviewRepository) GetInterview(publicID string) (*models.InterviewResults, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+func (r *interviewRepository) GetInterview(ctx context.Context, publicID string) (*models.InterviewResults, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
 	defer cancel()
 
 	query := `
-		SELECT i.public_id, c.public_id, i.results
+		SELECT i.public_id, c.public_id, i.status, i.results
 		FROM interviews AS i
 		LEFT JOIN user_interviews ui ON ui.interview_id = i.id
 		LEFT JOIN candidates c ON c.id = ui.candidate_id
-		GROUP BY i.public_id, c.public_id, i.results
+		WHERE i.public_id = $1
+		GROUP BY i.public_id, c.public_id, i.status, i.results
 	`
 	var resultBytes []byte
 	interview := &models.InterviewResults{}
-	err := r.db.QueryRow(ctx, query).Scan(&interview.PublicID, &interview.CandidatePublicID, &resultBytes)
+	err := conn(ctx, r.db).QueryRow(ctx, query, publicID).Scan(&interview.PublicID, &interview.CandidatePublicID, &interview.Status, &resultBytes)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrInterviewNotFound
+		}
 		r.logger.Errorf("Error occurred while retrieving interview result: %v", err)
 		return nil, err
 	}
 
-	err = json.Unmarshal(resultBytes, &interview.Result)
+	if resultBytes != nil {
+		err = json.Unmarshal(resultBytes, &interview.Result)
+		if err != nil {
+			r.logger.Errorf("Error occurred while unmarshll: %v", err)
+			return nil, err
+		}
+	}
+
+	return interview, nil
+}
+
+func (r *interviewRepository) GetSession(ctx context.Context, publicID string) (*models.InterviewSession, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT i.public_id, i.status, i.started_at, i.deadline, i.submitted_at,
+			(SELECT COUNT(DISTINCT q.id) FROM questions q
+				JOIN user_interviews ui ON ui.position_id = q.position_id
+				WHERE ui.interview_id = i.id),
+			(SELECT COUNT(*) FROM interview_questions iq
+				WHERE iq.interview_public_id = i.public_id AND iq.answered_at IS NOT NULL)
+		FROM interviews AS i
+		WHERE i.public_id = $1;
+	`
+
+	session := &models.InterviewSession{}
+	err := conn(ctx, r.db).QueryRow(ctx, query, publicID).Scan(&session.PublicID, &session.Status, &session.StartedAt, &session.Deadline,
+		&session.SubmittedAt, &session.QuestionsTotal, &session.QuestionsAnswered)
 	if err != nil {
-		r.logger.Errorf("Error occurred while unmarshll: %v", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrInterviewNotFound
+		}
+		r.logger.Errorf("Error occurred while retrieving interview session: %v", err)
 		return nil, err
 	}
+	return session, nil
+}
 
-	return interview, nil
+// GetInterviewCompany returns the company the interview was created for.
+// Interviews outside of a company get an empty company on the free plan.
+func (r *interviewRepository) GetInterviewCompany(ctx context.Context, publicID string) (*models.CompanyPlan, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT COALESCE(co.public_id::text, ''), COALESCE(co.plan, 'free')
+		FROM interviews AS i
+		LEFT JOIN user_interviews ui ON ui.interview_id = i.id
+		LEFT JOIN positions p ON p.id = ui.position_id
+		LEFT JOIN recruiters r ON r.public_id = p.recruiter_public_id
+		LEFT JOIN companies co ON co.public_id = r.company_public_id
+		WHERE i.public_id = $1;
+	`
+
+	company := &models.CompanyPlan{}
+	err := conn(ctx, r.db).QueryRow(ctx, query, publicID).Scan(&company.CompanyPublicID, &company.Plan)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrInterviewNotFound
+		}
+		r.logger.Errorf("Error occurred while retrieving interview company: %v", err)
+		return nil, err
+	}
+	return company, nil
+}
+
+func (r *interviewRepository) GetSessionQuestions(ctx context.Context, publicID string) ([]*models.SessionQuestion, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT q.public_id, COALESCE(q.name, ''), COALESCE(q.time_limit, 0), iq.started_at, iq.deadline, iq.answered_at
+		FROM questions AS q
+		JOIN user_interviews ui ON ui.position_id = q.position_id
+		JOIN interviews i ON i.id = ui.interview_id
+		LEFT JOIN interview_questions iq ON iq.interview_public_id = i.public_id AND iq.question_public_id = q.public_id
+		WHERE i.public_id = $1
+		GROUP BY q.id, q.public_id, q.name, q.time_limit, iq.started_at, iq.deadline, iq.answered_at
+		ORDER BY q.id;
+	`
+
+	result := make([]*models.SessionQuestion, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, query, publicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while retrieving interview questions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		q := &models.SessionQuestion{Number: len(result) + 1}
+		err = rows.Scan(&q.PublicID, &q.Question, &q.TimeLimit, &q.StartedAt, &q.Deadline, &q.AnsweredAt)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result = append(result, q)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *interviewRepository) StartInterview(ctx context.Context, publicID string, deadline time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		WITH changed AS (
+			UPDATE interviews
+			SET status = $2, started_at = NOW(), deadline = $3
+			WHERE public_id = $1 AND status = $4
+			RETURNING public_id, status
+		)
+	` + insertStatusEvents
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, publicID, models.InterviewStatusInProgress, deadline, models.InterviewStatusPending)
+	if err != nil {
+		r.logger.Errorf("Error occurred while starting interview: %v", err)
+		return false, err
+	}
+	return tag.RowsAffected() != 0, nil
+}
+
+func (r *interviewRepository) TransitionInterview(ctx context.Context, publicID string, from []string, to string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		WITH changed AS (
+			UPDATE interviews
+			SET status = $2::text,
+				submitted_at = CASE WHEN $2::text = $4::text THEN NOW() ELSE submitted_at END
+			WHERE public_id = $1 AND status = ANY($3::text[])
+			RETURNING public_id, status
+		)
+	` + insertStatusEvents
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, publicID, to, from, models.InterviewStatusSubmitted)
+	if err != nil {
+		r.logger.Errorf("Error occurred while updating interview status: %v", err)
+		return false, err
+	}
+	return tag.RowsAffected() != 0, nil
+}
+
+func (r *interviewRepository) StartQuestion(ctx context.Context, interviewPublicID, questionPublicID string, deadline time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		INSERT INTO interview_questions (interview_public_id, question_public_id, deadline)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (interview_public_id, question_public_id) DO NOTHING;
+	`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, interviewPublicID, questionPublicID, deadline)
+	if err != nil {
+		r.logger.Errorf("Error occurred while starting question: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *interviewRepository) AnswerQuestion(ctx context.Context, interviewPublicID, questionPublicID, video string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Error occurred while starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE interview_questions
+		SET answered_at = NOW()
+		WHERE interview_public_id = $1 AND question_public_id = $2 AND answered_at IS NULL;
+	`, interviewPublicID, questionPublicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while answering question: %v", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrQuestionAnswered
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO videos (interviews_public_id, question_public_id, path)
+		VALUES ($1, $2, $3);
+	`, interviewPublicID, questionPublicID, video)
+	if err != nil {
+		r.logger.Errorf("Error occurred while adding video to question: %v", err)
+		return err
+	}
+
+	if err = addVideoEvent(ctx, tx, interviewPublicID, questionPublicID, video); err != nil {
+		r.logger.Errorf("Error occurred while recording outbox event: %v", err)
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Error occurred while committing answer: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ClaimDeadlineReminders marks the interviews in progress whose deadline
+// falls before deadlineBefore as reminded and returns them.
+func (r *interviewRepository) ClaimDeadlineReminders(ctx context.Context, deadlineBefore time.Time) ([]*models.InterviewSession, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		UPDATE interviews
+		SET reminded_at = NOW()
+		WHERE status = $1 AND reminded_at IS NULL
+		AND deadline > NOW() AND deadline <= $2
+		RETURNING public_id, status, started_at, deadline;
+	`
+
+	result := make([]*models.InterviewSession, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, query, models.InterviewStatusInProgress, deadlineBefore)
+	if err != nil {
+		r.logger.Errorf("Error occurred while claiming interview reminders: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		session := &models.InterviewSession{}
+		err = rows.Scan(&session.PublicID, &session.Status, &session.StartedAt, &session.Deadline)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result = append(result, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ReleaseDeadlineReminder lets the interview be reminded again, after its
+// reminder could not be sent.
+func (r *interviewRepository) ReleaseDeadlineReminder(ctx context.Context, publicID string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE interviews SET reminded_at = NULL WHERE public_id = $1;`, publicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while releasing interview reminder: %v", err)
+		return err
+	}
+	return nil
+}
+
+// SubmitOverdueInterviews submits the interviews still in progress after
+// their deadline that have answers, so that the answers are analyzed, and
+// returns them.
+func (r *interviewRepository) SubmitOverdueInterviews(ctx context.Context, deadlineBefore time.Time) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		WITH changed AS (
+			UPDATE interviews AS i
+			SET status = $1, submitted_at = NOW()
+			WHERE i.status = $2 AND i.deadline < $3
+			AND EXISTS (
+				SELECT 1 FROM interview_questions iq
+				WHERE iq.interview_public_id = i.public_id AND iq.answered_at IS NOT NULL
+			)
+			RETURNING i.public_id, i.status
+		), events AS (
+			INSERT INTO outbox (aggregate_id, event, payload)
+			SELECT public_id, 'interview.' || status, jsonb_build_object('status', status)
+			FROM changed
+		)
+		SELECT public_id FROM changed;
+	`
+
+	result := make([]string, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, query, models.InterviewStatusSubmitted, models.InterviewStatusInProgress, deadlineBefore)
+	if err != nil {
+		r.logger.Errorf("Error occurred while submitting overdue interviews: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var publicID string
+		if err = rows.Scan(&publicID); err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result = append(result, publicID)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *interviewRepository) ExpireInterviews(ctx context.Context, deadlineBefore time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		WITH changed AS (
+			UPDATE interviews
+			SET status = $1
+			WHERE status = $2 AND deadline < $3
+			RETURNING public_id, status
+		)
+	` + insertStatusEvents
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, models.InterviewStatusExpired, models.InterviewStatusInProgress, deadlineBefore)
+	if err != nil {
+		r.logger.Errorf("Error occurred while expiring interviews: %v", err)
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// AddResultVersion keeps result as the next version of the interview's
+// result and returns its number.
+func (r *interviewRepository) AddResultVersion(ctx context.Context, publicID, source string, result *models.Result) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		r.logger.Errorf("Failed to marshal interview results to JSON: %v", err)
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO interview_result_versions (interview_public_id, version, source, score, result)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4
+		FROM interview_result_versions
+		WHERE interview_public_id = $1
+		RETURNING version;
+	`
+	var version int
+	err = conn(ctx, r.db).QueryRow(ctx, query, publicID, source, result.Score, data).Scan(&version)
+	if err != nil {
+		r.logger.Errorf("Error occurred while adding result version: %v", err)
+		return 0, err
+	}
+	return version, nil
+}
+
+func (r *interviewRepository) GetResultVersions(ctx context.Context, publicID string) ([]*models.ResultVersion, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT version, source, score, result, created_at
+		FROM interview_result_versions
+		WHERE interview_public_id = $1
+		ORDER BY version;
+	`
+	rows, err := conn(ctx, r.db).Query(ctx, query, publicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while retrieving result versions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make([]*models.ResultVersion, 0)
+	for rows.Next() {
+		version := &models.ResultVersion{}
+		var resultBytes []byte
+		if err = rows.Scan(&version.Version, &version.Source, &version.Score, &resultBytes, &version.CreatedAt); err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		if err = json.Unmarshal(resultBytes, &version.Result); err != nil {
+			r.logger.Errorf("Error occurred while unmarshalling result version: %v", err)
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+	return versions, nil
+}
+
+func addVideoEvent(ctx context.Context, tx pgx.Tx, interviewPublicID, questionPublicID, video string) error {
+	return addOutboxEvent(ctx, tx, interviewPublicID, models.OutboxVideoAdded, map[string]string{
+		"question_public_id": questionPublicID,
+		"video":              video,
+	})
 }
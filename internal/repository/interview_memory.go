@@ -0,0 +1,504 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+)
+
+// MemoryInterview seeds an interview of MemoryInterviewRepository along
+// with the questions of its position, in order.
+type MemoryInterview struct {
+	PublicID          string
+	CandidatePublicID string
+	CompanyPublicID   string
+	Plan              string
+	Status            string
+	Questions         []MemoryQuestion
+}
+
+type MemoryQuestion struct {
+	PublicID  string
+	Question  string
+	TimeLimit int
+}
+
+type memoryInterview struct {
+	seed        MemoryInterview
+	status      string
+	results     []byte
+	startedAt   *time.Time
+	deadline    *time.Time
+	submittedAt *time.Time
+	remindedAt  *time.Time
+	versions    []*models.ResultVersion
+}
+
+type memoryVideo struct {
+	publicID          string
+	interviewPublicID string
+	questionPublicID  string
+	path              string
+}
+
+type memoryQuestionState struct {
+	startedAt  time.Time
+	deadline   time.Time
+	answeredAt *time.Time
+}
+
+// MemoryInterviewRepository is an InterviewRepository kept in memory, for
+// tests and local development. It behaves like the Postgres one, including
+// the outbox events recorded along with the writes.
+type MemoryInterviewRepository struct {
+	mu         sync.Mutex
+	interviews map[string]*memoryInterview
+	order      []string
+	videos     []*memoryVideo
+	questions  map[[2]string]*memoryQuestionState
+	events     []*models.OutboxEvent
+}
+
+var _ InterviewRepository = (*MemoryInterviewRepository)(nil)
+
+func NewMemoryInterviewRepository() *MemoryInterviewRepository {
+	return &MemoryInterviewRepository{
+		interviews: make(map[string]*memoryInterview),
+		questions:  make(map[[2]string]*memoryQuestionState),
+	}
+}
+
+// AddInterview adds or replaces an interview. An empty status is pending
+// and an empty plan is free.
+func (r *MemoryInterviewRepository) AddInterview(seed MemoryInterview) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if seed.Status == "" {
+		seed.Status = models.InterviewStatusPending
+	}
+	if seed.Plan == "" {
+		seed.Plan = "free"
+	}
+	seed.Questions = append([]MemoryQuestion(nil), seed.Questions...)
+	if _, ok := r.interviews[seed.PublicID]; !ok {
+		r.order = append(r.order, seed.PublicID)
+	}
+	r.interviews[seed.PublicID] = &memoryInterview{seed: seed, status: seed.Status}
+}
+
+// OutboxEvents returns the events recorded so far, oldest first.
+func (r *MemoryInterviewRepository) OutboxEvents() []*models.OutboxEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*models.OutboxEvent(nil), r.events...)
+}
+
+func (r *MemoryInterviewRepository) GetInterviewByPublicID(ctx context.Context, publicID string) (*models.InterviewResults, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	interview, ok := r.interviews[publicID]
+	if !ok || interview.seed.CandidatePublicID == "" {
+		return nil, models.ErrInterviewNotFound
+	}
+
+	result := &models.InterviewResults{CandidatePublicID: interview.seed.CandidatePublicID}
+	for _, q := range interview.seed.Questions {
+		for _, v := range r.videos {
+			if v.interviewPublicID != publicID || v.questionPublicID != q.PublicID {
+				continue
+			}
+			result.Result.Questions = append(result.Result.Questions, models.QuestionResult{
+				Question:       q.Question,
+				PublicID:       q.PublicID,
+				VideoLink:      v.path,
+				VideoPublicID:  v.publicID,
+				EmotionResults: make([]models.EmotionResult, 0),
+			})
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryInterviewRepository) PutInterview(ctx context.Context, interview *models.InterviewResults) error {
+	jsonData, err := json.Marshal(interview.Result)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.interviews[interview.PublicID]; ok {
+		stored.results = jsonData
+	}
+	event := models.OutboxResultSaved
+	if interview.Result.Override != nil {
+		event = models.OutboxScoreOverridden
+	}
+	return r.addEvent(interview.PublicID, event, map[string]interface{}{
+		"score":    interview.Result.Score,
+		"override": interview.Result.Override,
+	})
+}
+
+func (r *MemoryInterviewRepository) AddVideoToQuestion(ctx context.Context, questionPublicID, interviewPublicID, video string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.addVideo(interviewPublicID, questionPublicID, video)
+}
+
+func (r *MemoryInterviewRepository) GetAllInterviews(ctx context.Context) ([]*models.InterviewResults, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]*models.InterviewResults, 0, len(r.order))
+	for _, publicID := range r.order {
+		interview, err := r.interview(publicID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, interview)
+	}
+	return result, nil
+}
+
+func (r *MemoryInterviewRepository) GetInterview(ctx context.Context, publicID string) (*models.InterviewResults, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.interview(publicID)
+}
+
+func (r *MemoryInterviewRepository) GetSession(ctx context.Context, publicID string) (*models.InterviewSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	interview, ok := r.interviews[publicID]
+	if !ok {
+		return nil, models.ErrInterviewNotFound
+	}
+	session := &models.InterviewSession{
+		PublicID:       publicID,
+		Status:         interview.status,
+		StartedAt:      copyTime(interview.startedAt),
+		Deadline:       copyTime(interview.deadline),
+		SubmittedAt:    copyTime(interview.submittedAt),
+		QuestionsTotal: len(interview.seed.Questions),
+	}
+	for key, q := range r.questions {
+		if key[0] == publicID && q.answeredAt != nil {
+			session.QuestionsAnswered++
+		}
+	}
+	return session, nil
+}
+
+func (r *MemoryInterviewRepository) GetInterviewCompany(ctx context.Context, publicID string) (*models.CompanyPlan, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	interview, ok := r.interviews[publicID]
+	if !ok {
+		return nil, models.ErrInterviewNotFound
+	}
+	return &models.CompanyPlan{
+		CompanyPublicID: interview.seed.CompanyPublicID,
+		Plan:            interview.seed.Plan,
+	}, nil
+}
+
+func (r *MemoryInterviewRepository) GetSessionQuestions(ctx context.Context, publicID string) ([]*models.SessionQuestion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]*models.SessionQuestion, 0)
+	interview, ok := r.interviews[publicID]
+	if !ok {
+		return result, nil
+	}
+	for i, q := range interview.seed.Questions {
+		question := &models.SessionQuestion{
+			PublicID:  q.PublicID,
+			Question:  q.Question,
+			Number:    i + 1,
+			TimeLimit: q.TimeLimit,
+		}
+		if state, ok := r.questions[[2]string{publicID, q.PublicID}]; ok {
+			question.StartedAt = copyTime(&state.startedAt)
+			question.Deadline = copyTime(&state.deadline)
+			question.AnsweredAt = copyTime(state.answeredAt)
+		}
+		result = append(result, question)
+	}
+	return result, nil
+}
+
+func (r *MemoryInterviewRepository) StartInterview(ctx context.Context, publicID string, deadline time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	interview, ok := r.interviews[publicID]
+	if !ok || interview.status != models.InterviewStatusPending {
+		return false, nil
+	}
+	now := time.Now()
+	interview.startedAt = &now
+	interview.deadline = &deadline
+	return true, r.setStatus(interview, models.InterviewStatusInProgress)
+}
+
+func (r *MemoryInterviewRepository) TransitionInterview(ctx context.Context, publicID string, from []string, to string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	interview, ok := r.interviews[publicID]
+	if !ok || !containsStatus(from, interview.status) {
+		return false, nil
+	}
+	if to == models.InterviewStatusSubmitted {
+		now := time.Now()
+		interview.submittedAt = &now
+	}
+	return true, r.setStatus(interview, to)
+}
+
+func (r *MemoryInterviewRepository) StartQuestion(ctx context.Context, interviewPublicID, questionPublicID string, deadline time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.interviews[interviewPublicID]; !ok {
+		return models.ErrInterviewNotFound
+	}
+	key := [2]string{interviewPublicID, questionPublicID}
+	if _, ok := r.questions[key]; !ok {
+		r.questions[key] = &memoryQuestionState{startedAt: time.Now(), deadline: deadline}
+	}
+	return nil
+}
+
+func (r *MemoryInterviewRepository) AnswerQuestion(ctx context.Context, interviewPublicID, questionPublicID, video string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.questions[[2]string{interviewPublicID, questionPublicID}]
+	if !ok || state.answeredAt != nil {
+		return models.ErrQuestionAnswered
+	}
+	now := time.Now()
+	state.answeredAt = &now
+	return r.addVideo(interviewPublicID, questionPublicID, video)
+}
+
+func (r *MemoryInterviewRepository) ClaimDeadlineReminders(ctx context.Context, deadlineBefore time.Time) ([]*models.InterviewSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	result := make([]*models.InterviewSession, 0)
+	for _, publicID := range r.order {
+		interview := r.interviews[publicID]
+		if interview.status != models.InterviewStatusInProgress || interview.remindedAt != nil || interview.deadline == nil ||
+			!interview.deadline.After(now) || interview.deadline.After(deadlineBefore) {
+			continue
+		}
+		interview.remindedAt = &now
+		result = append(result, &models.InterviewSession{
+			PublicID:  publicID,
+			Status:    interview.status,
+			StartedAt: copyTime(interview.startedAt),
+			Deadline:  copyTime(interview.deadline),
+		})
+	}
+	return result, nil
+}
+
+func (r *MemoryInterviewRepository) ReleaseDeadlineReminder(ctx context.Context, publicID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if interview, ok := r.interviews[publicID]; ok {
+		interview.remindedAt = nil
+	}
+	return nil
+}
+
+func (r *MemoryInterviewRepository) SubmitOverdueInterviews(ctx context.Context, deadlineBefore time.Time) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]string, 0)
+	for _, publicID := range r.order {
+		interview := r.interviews[publicID]
+		if interview.status != models.InterviewStatusInProgress || interview.deadline == nil || !interview.deadline.Before(deadlineBefore) {
+			continue
+		}
+		answered := false
+		for key, state := range r.questions {
+			if key[0] == publicID && state.answeredAt != nil {
+				answered = true
+				break
+			}
+		}
+		if !answered {
+			continue
+		}
+		now := time.Now()
+		interview.submittedAt = &now
+		if err := r.setStatus(interview, models.InterviewStatusSubmitted); err != nil {
+			return result, err
+		}
+		result = append(result, publicID)
+	}
+	return result, nil
+}
+
+func (r *MemoryInterviewRepository) ExpireInterviews(ctx context.Context, deadlineBefore time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expired := 0
+	for _, publicID := range r.order {
+		interview := r.interviews[publicID]
+		if interview.status != models.InterviewStatusInProgress || interview.deadline == nil || !interview.deadline.Before(deadlineBefore) {
+			continue
+		}
+		if err := r.setStatus(interview, models.InterviewStatusExpired); err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+func (r *MemoryInterviewRepository) AddResultVersion(ctx context.Context, publicID, source string, result *models.Result) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	interview, ok := r.interviews[publicID]
+	if !ok {
+		return 0, models.ErrInterviewNotFound
+	}
+	// keep a copy, as the caller may go on changing result
+	data, err := json.Marshal(result)
+	if err != nil {
+		return 0, err
+	}
+	version := &models.ResultVersion{
+		Version:   len(interview.versions) + 1,
+		Source:    source,
+		Score:     result.Score,
+		CreatedAt: time.Now(),
+	}
+	if err = json.Unmarshal(data, &version.Result); err != nil {
+		return 0, err
+	}
+	interview.versions = append(interview.versions, version)
+	return version.Version, nil
+}
+
+func (r *MemoryInterviewRepository) GetResultVersions(ctx context.Context, publicID string) ([]*models.ResultVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	versions := make([]*models.ResultVersion, 0)
+	if interview, ok := r.interviews[publicID]; ok {
+		for _, v := range interview.versions {
+			version := *v
+			versions = append(versions, &version)
+		}
+	}
+	return versions, nil
+}
+
+func (r *MemoryInterviewRepository) interview(publicID string) (*models.InterviewResults, error) {
+	stored, ok := r.interviews[publicID]
+	if !ok {
+		return nil, models.ErrInterviewNotFound
+	}
+	interview := &models.InterviewResults{
+		PublicID:          publicID,
+		CandidatePublicID: stored.seed.CandidatePublicID,
+		Status:            stored.status,
+	}
+	if stored.results != nil {
+		if err := json.Unmarshal(stored.results, &interview.Result); err != nil {
+			return nil, err
+		}
+	}
+	return interview, nil
+}
+
+func (r *MemoryInterviewRepository) setStatus(interview *memoryInterview, status string) error {
+	interview.status = status
+	payload := map[string]interface{}{
+		"status":   status,
+		"score":    nil,
+		"override": nil,
+	}
+	if interview.results != nil {
+		var result models.Result
+		if err := json.Unmarshal(interview.results, &result); err != nil {
+			return err
+		}
+		payload["score"] = result.Score
+		payload["override"] = result.Override
+	}
+	return r.addEvent(interview.seed.PublicID, models.OutboxInterviewEventPrefix+status, payload)
+}
+
+func (r *MemoryInterviewRepository) addVideo(interviewPublicID, questionPublicID, path string) error {
+	publicID, err := newUUID()
+	if err != nil {
+		return err
+	}
+	r.videos = append(r.videos, &memoryVideo{
+		publicID:          publicID,
+		interviewPublicID: interviewPublicID,
+		questionPublicID:  questionPublicID,
+		path:              path,
+	})
+	return r.addEvent(interviewPublicID, models.OutboxVideoAdded, map[string]string{
+		"question_public_id": questionPublicID,
+		"video":              path,
+	})
+}
+
+func (r *MemoryInterviewRepository) addEvent(aggregateID, event string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	r.events = append(r.events, &models.OutboxEvent{
+		ID:          int64(len(r.events) + 1),
+		AggregateID: aggregateID,
+		Event:       event,
+		Payload:     data,
+		Handled:     make([]string, 0),
+		CreatedAt:   time.Now(),
+	})
+	return nil
+}
+
+func containsStatus(statuses []string, status string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// MemoryTxManager runs units of work directly, for use along with
+// MemoryInterviewRepository. It neither isolates them nor rolls them back.
+type MemoryTxManager struct{}
+
+func (MemoryTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,458 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+// interviewSeeder adds the interviews testInterviewRepository works on to
+// the repository under test.
+type interviewSeeder interface {
+	AddInterview(seed MemoryInterview)
+}
+
+func TestMemoryInterviewRepository(t *testing.T) {
+	testInterviewRepository(t, NewMemoryInterviewRepository())
+}
+
+// TestPostgresInterviewRepository runs against the database at
+// DATABASE_URL, set up with scripts/init.sql. Every run adds interviews of
+// its own and leaves the others alone.
+func TestPostgresInterviewRepository(t *testing.T) {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+	db, err := pgxpool.Connect(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewInterviewRepository(db, &config.DBConf{TimeOut: 5 * time.Second}, zap.NewNop().Sugar())
+	testInterviewRepository(t, &seededInterviewRepository{InterviewRepository: repo, t: t, db: db})
+}
+
+// seededInterviewRepository seeds the Postgres repository with interviews
+// along with their candidate, company, position and questions.
+type seededInterviewRepository struct {
+	InterviewRepository
+	t  *testing.T
+	db *pgxpool.Pool
+}
+
+func (r *seededInterviewRepository) AddInterview(seed MemoryInterview) {
+	r.t.Helper()
+	if seed.Status == "" {
+		seed.Status = models.InterviewStatusPending
+	}
+	if seed.Plan == "" {
+		seed.Plan = "free"
+	}
+	recruiterPublicID := mustUUID(r.t)
+	ctx := context.Background()
+	var candidateID, positionID, interviewID int
+	err := r.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `INSERT INTO users (public_id, first_name) VALUES ($1, 'Candidate'), ($2, 'Recruiter')`,
+			seed.CandidatePublicID, recruiterPublicID); err != nil {
+			return err
+		}
+		if err := tx.QueryRow(ctx, `INSERT INTO candidates (public_id) VALUES ($1) RETURNING id`, seed.CandidatePublicID).Scan(&candidateID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO companies (public_id, name, plan) VALUES ($1, 'Company', $2)`, seed.CompanyPublicID, seed.Plan); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO recruiters (public_id, company_public_id) VALUES ($1, $2)`, recruiterPublicID, seed.CompanyPublicID); err != nil {
+			return err
+		}
+		if err := tx.QueryRow(ctx, `INSERT INTO positions (name, recruiter_public_id) VALUES ('Position', $1) RETURNING id`, recruiterPublicID).Scan(&positionID); err != nil {
+			return err
+		}
+		for _, q := range seed.Questions {
+			if _, err := tx.Exec(ctx, `INSERT INTO questions (public_id, name, position_id, time_limit) VALUES ($1, $2, $3, $4)`,
+				q.PublicID, q.Question, positionID, q.TimeLimit); err != nil {
+				return err
+			}
+		}
+		if err := tx.QueryRow(ctx, `INSERT INTO interviews (public_id, status) VALUES ($1, $2) RETURNING id`, seed.PublicID, seed.Status).Scan(&interviewID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO user_interviews (candidate_id, position_id, interview_id) VALUES ($1, $2, $3)`, candidateID, positionID, interviewID)
+		return err
+	})
+	if err != nil {
+		r.t.Fatalf("could not seed interview %s: %v", seed.PublicID, err)
+	}
+}
+
+// testInterviewRepository checks the behavior every InterviewRepository
+// shares. repo must implement interviewSeeder.
+func testInterviewRepository(t *testing.T, repo InterviewRepository) {
+	seeder, ok := repo.(interviewSeeder)
+	if !ok {
+		t.Fatalf("%T can't be seeded with interviews", repo)
+	}
+	// seed adds an interview with two questions in status
+	seed := func(t *testing.T, status string) (string, []MemoryQuestion) {
+		t.Helper()
+		interview := MemoryInterview{
+			PublicID:          mustUUID(t),
+			CandidatePublicID: mustUUID(t),
+			CompanyPublicID:   mustUUID(t),
+			Plan:              "pro",
+			Status:            status,
+			Questions: []MemoryQuestion{
+				{PublicID: mustUUID(t), Question: "Tell us about yourself.", TimeLimit: 120},
+				{PublicID: mustUUID(t), Question: "Why this position?", TimeLimit: 90},
+			},
+		}
+		seeder.AddInterview(interview)
+		return interview.PublicID, interview.Questions
+	}
+	ctx := context.Background()
+
+	t.Run("not found", func(t *testing.T) {
+		missing := mustUUID(t)
+		if _, err := repo.GetInterview(ctx, missing); !errors.Is(err, models.ErrInterviewNotFound) {
+			t.Errorf("GetInterview() error = %v, want %v", err, models.ErrInterviewNotFound)
+		}
+		if _, err := repo.GetSession(ctx, missing); !errors.Is(err, models.ErrInterviewNotFound) {
+			t.Errorf("GetSession() error = %v, want %v", err, models.ErrInterviewNotFound)
+		}
+		if _, err := repo.GetInterviewCompany(ctx, missing); !errors.Is(err, models.ErrInterviewNotFound) {
+			t.Errorf("GetInterviewCompany() error = %v, want %v", err, models.ErrInterviewNotFound)
+		}
+		if started, err := repo.StartInterview(ctx, missing, time.Now().Add(time.Hour)); err != nil || started {
+			t.Errorf("StartInterview() = %v, %v, want false", started, err)
+		}
+		if changed, err := repo.TransitionInterview(ctx, missing, []string{models.InterviewStatusInProgress}, models.InterviewStatusSubmitted); err != nil || changed {
+			t.Errorf("TransitionInterview() = %v, %v, want false", changed, err)
+		}
+		questions, err := repo.GetSessionQuestions(ctx, missing)
+		if err != nil || len(questions) != 0 {
+			t.Errorf("GetSessionQuestions() = %d questions, %v, want none", len(questions), err)
+		}
+	})
+
+	t.Run("session", func(t *testing.T) {
+		publicID, questions := seed(t, "")
+		session, err := repo.GetSession(ctx, publicID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if session.Status != models.InterviewStatusPending || session.QuestionsTotal != 2 || session.QuestionsAnswered != 0 || session.StartedAt != nil {
+			t.Errorf("session = %+v, want pending with 2 questions and none answered", session)
+		}
+		company, err := repo.GetInterviewCompany(ctx, publicID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if company.Plan != "pro" {
+			t.Errorf("plan = %q, want pro", company.Plan)
+		}
+
+		deadline := time.Now().Add(time.Hour).Truncate(time.Second)
+		if started, err := repo.StartInterview(ctx, publicID, deadline); err != nil || !started {
+			t.Fatalf("StartInterview() = %v, %v, want true", started, err)
+		}
+		if started, err := repo.StartInterview(ctx, publicID, deadline); err != nil || started {
+			t.Errorf("second StartInterview() = %v, %v, want false", started, err)
+		}
+
+		if err = repo.StartQuestion(ctx, publicID, questions[0].PublicID, time.Now().Add(time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		if err = repo.AnswerQuestion(ctx, publicID, questions[1].PublicID, "videos/unstarted.mp4"); !errors.Is(err, models.ErrQuestionAnswered) {
+			t.Errorf("AnswerQuestion() of an unstarted question error = %v, want %v", err, models.ErrQuestionAnswered)
+		}
+		if err = repo.AnswerQuestion(ctx, publicID, questions[0].PublicID, "videos/first.mp4"); err != nil {
+			t.Fatal(err)
+		}
+		if err = repo.AnswerQuestion(ctx, publicID, questions[0].PublicID, "videos/again.mp4"); !errors.Is(err, models.ErrQuestionAnswered) {
+			t.Errorf("second AnswerQuestion() error = %v, want %v", err, models.ErrQuestionAnswered)
+		}
+
+		session, err = repo.GetSession(ctx, publicID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if session.Status != models.InterviewStatusInProgress || session.QuestionsAnswered != 1 ||
+			session.Deadline == nil || !session.Deadline.Equal(deadline) {
+			t.Errorf("session = %+v, want in progress until %v with 1 question answered", session, deadline)
+		}
+		sessionQuestions, err := repo.GetSessionQuestions(ctx, publicID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sessionQuestions) != 2 {
+			t.Fatalf("got %d session questions, want 2", len(sessionQuestions))
+		}
+		for i, q := range sessionQuestions {
+			if q.PublicID != questions[i].PublicID || q.Number != i+1 || q.TimeLimit != questions[i].TimeLimit {
+				t.Errorf("session question %d = %+v, want %s numbered %d", i, q, questions[i].PublicID, i+1)
+			}
+		}
+		if sessionQuestions[0].AnsweredAt == nil || sessionQuestions[1].StartedAt != nil {
+			t.Errorf("session questions = %+v, %+v, want the first answered and the second not started", sessionQuestions[0], sessionQuestions[1])
+		}
+		interview, err := repo.GetInterviewByPublicID(ctx, publicID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(interview.Result.Questions) != 1 || interview.Result.Questions[0].VideoLink != "videos/first.mp4" {
+			t.Errorf("answered questions = %+v, want the first one", interview.Result.Questions)
+		}
+	})
+
+	t.Run("transitions", func(t *testing.T) {
+		publicID, _ := seed(t, models.InterviewStatusInProgress)
+		transition := func(from []string, to string) bool {
+			t.Helper()
+			changed, err := repo.TransitionInterview(ctx, publicID, from, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return changed
+		}
+
+		if transition([]string{models.InterviewStatusPending}, models.InterviewStatusExpired) {
+			t.Error("interview in progress left pending")
+		}
+		if !transition([]string{models.InterviewStatusInProgress}, models.InterviewStatusSubmitted) {
+			t.Fatal("interview in progress not submitted")
+		}
+		session, err := repo.GetSession(ctx, publicID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if session.Status != models.InterviewStatusSubmitted || session.SubmittedAt == nil {
+			t.Errorf("session = %+v, want submitted with its submission time", session)
+		}
+		if !transition([]string{models.InterviewStatusSubmitted, models.InterviewStatusFailed}, models.InterviewStatusEvaluated) {
+			t.Fatal("submitted interview not evaluated")
+		}
+		if transition([]string{models.InterviewStatusInProgress}, models.InterviewStatusSubmitted) {
+			t.Error("evaluated interview submitted again")
+		}
+		interview, err := repo.GetInterview(ctx, publicID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if interview.Status != models.InterviewStatusEvaluated {
+			t.Errorf("status = %q, want %q", interview.Status, models.InterviewStatusEvaluated)
+		}
+	})
+
+	t.Run("results", func(t *testing.T) {
+		publicID, questions := seed(t, models.InterviewStatusSubmitted)
+		result := models.Result{
+			Questions: []models.QuestionResult{{PublicID: questions[0].PublicID, Score: 6, EmotionResults: make([]models.EmotionResult, 0)}},
+			Score:     6,
+		}
+		if err := repo.PutInterview(ctx, &models.InterviewResults{PublicID: publicID, Result: result}); err != nil {
+			t.Fatal(err)
+		}
+		for _, score := range []int{6, 9} {
+			result.Score = score
+			if _, err := repo.AddResultVersion(ctx, publicID, models.ResultSourceAnalysis, &result); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		interview, err := repo.GetInterview(ctx, publicID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if interview.Result.Score != 6 || len(interview.Result.Questions) != 1 {
+			t.Errorf("result = %+v, want the one put with score 6", interview.Result)
+		}
+		versions, err := repo.GetResultVersions(ctx, publicID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(versions) != 2 || versions[0].Version != 1 || versions[0].Score != 6 || versions[1].Version != 2 || versions[1].Score != 9 {
+			t.Errorf("versions = %+v, want 1 with score 6 and 2 with score 9", versions)
+		}
+	})
+
+	t.Run("reminders", func(t *testing.T) {
+		due, _ := seed(t, "")
+		later, _ := seed(t, "")
+		now := time.Now()
+		startInterview(t, repo, due, now.Add(5*time.Minute))
+		startInterview(t, repo, later, now.Add(time.Hour))
+
+		claimed := claimReminders(t, repo, now.Add(10*time.Minute), due, later)
+		if !equalIDs(claimed, due) {
+			t.Fatalf("claimed %v, want [%s]", claimed, due)
+		}
+		if claimed = claimReminders(t, repo, now.Add(10*time.Minute), due, later); len(claimed) != 0 {
+			t.Errorf("claimed %v again, want none", claimed)
+		}
+		if err := repo.ReleaseDeadlineReminder(ctx, due); err != nil {
+			t.Fatal(err)
+		}
+		if claimed = claimReminders(t, repo, now.Add(10*time.Minute), due, later); !equalIDs(claimed, due) {
+			t.Errorf("claimed %v after the release, want [%s]", claimed, due)
+		}
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		answered, answeredQuestions := seed(t, "")
+		abandoned, _ := seed(t, "")
+		running, _ := seed(t, "")
+		now := time.Now()
+		startInterview(t, repo, answered, now.Add(-time.Hour))
+		startInterview(t, repo, abandoned, now.Add(-time.Hour))
+		startInterview(t, repo, running, now.Add(time.Hour))
+		if err := repo.StartQuestion(ctx, answered, answeredQuestions[0].PublicID, now.Add(-time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.AnswerQuestion(ctx, answered, answeredQuestions[0].PublicID, "videos/overdue.mp4"); err != nil {
+			t.Fatal(err)
+		}
+
+		submitted, err := repo.SubmitOverdueInterviews(ctx, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := filterIDs(submitted, answered, abandoned, running); !equalIDs(got, answered) {
+			t.Errorf("submitted %v, want [%s]", got, answered)
+		}
+		expired, err := repo.ExpireInterviews(ctx, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expired < 1 {
+			t.Errorf("expired %d interviews, want the abandoned one", expired)
+		}
+
+		for publicID, want := range map[string]string{
+			answered:  models.InterviewStatusSubmitted,
+			abandoned: models.InterviewStatusExpired,
+			running:   models.InterviewStatusInProgress,
+		} {
+			session, err := repo.GetSession(ctx, publicID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if session.Status != want {
+				t.Errorf("interview %s is %s, want %s", publicID, session.Status, want)
+			}
+		}
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		const workers = 8
+		publicID, _ := seed(t, models.InterviewStatusSubmitted)
+		reminded, _ := seed(t, "")
+		startInterview(t, repo, reminded, time.Now().Add(5*time.Minute))
+
+		var (
+			wg          sync.WaitGroup
+			mu          sync.Mutex
+			transitions int
+			claims      int
+		)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				changed, err := repo.TransitionInterview(ctx, publicID, []string{models.InterviewStatusSubmitted}, models.InterviewStatusEvaluated)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				sessions, err := repo.ClaimDeadlineReminders(ctx, time.Now().Add(10*time.Minute))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if changed {
+					transitions++
+				}
+				for _, s := range sessions {
+					if s.PublicID == reminded {
+						claims++
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		if transitions != 1 {
+			t.Errorf("interview evaluated by %d callers, want 1", transitions)
+		}
+		if claims != 1 {
+			t.Errorf("reminder claimed by %d callers, want 1", claims)
+		}
+	})
+}
+
+func startInterview(t *testing.T, repo InterviewRepository, publicID string, deadline time.Time) {
+	t.Helper()
+	if started, err := repo.StartInterview(context.Background(), publicID, deadline); err != nil || !started {
+		t.Fatalf("StartInterview(%s) = %v, %v, want true", publicID, started, err)
+	}
+}
+
+// claimReminders claims the reminders due before deadlineBefore and
+// returns those among publicIDs.
+func claimReminders(t *testing.T, repo InterviewRepository, deadlineBefore time.Time, publicIDs ...string) []string {
+	t.Helper()
+	sessions, err := repo.ClaimDeadlineReminders(context.Background(), deadlineBefore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimed := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		claimed = append(claimed, s.PublicID)
+	}
+	return filterIDs(claimed, publicIDs...)
+}
+
+// filterIDs returns the IDs among ids that are one of wanted, so that the
+// interviews of other tests sharing the database are left out.
+func filterIDs(ids []string, wanted ...string) []string {
+	result := make([]string, 0)
+	for _, id := range ids {
+		if containsStatus(wanted, id) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func equalIDs(ids []string, want ...string) bool {
+	if len(ids) != len(want) {
+		return false
+	}
+	for i := range ids {
+		if ids[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func mustUUID(t *testing.T) string {
+	t.Helper()
+	id, err := newUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
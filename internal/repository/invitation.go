@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+// invitations that were neither started nor revoked in time are reported as expired
+const selectInvitations = `
+	SELECT inv.public_id, inv.interview_public_id, c.public_id, p.public_id,
+		CASE WHEN inv.status IN ('sent', 'opened') AND inv.expires_at < NOW() THEN 'expired' ELSE inv.status END,
+		inv.expires_at, inv.sent_at, inv.opened_at, inv.started_at, inv.revoked_at, inv.resend_count, inv.nonce
+	FROM invitations AS inv
+	JOIN interviews i ON i.public_id = inv.interview_public_id
+	JOIN user_interviews ui ON ui.interview_id = i.id
+	JOIN candidates c ON c.id = ui.candidate_id
+	JOIN positions p ON p.id = ui.position_id
+`
+
+type invitationRepository struct {
+	db     *pgxpool.Pool
+	cfg    *config.DBConf
+	logger *zap.SugaredLogger
+}
+
+func NewInvitationRepository(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.SugaredLogger) InvitationRepository {
+	return &invitationRepository{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *invitationRepository) CreateInvitation(positionPublicID, candidatePublicID, nonce string, expiresAt time.Time) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Error occurred while starting transaction: %v", err)
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	var positionID, candidateID int
+	err = tx.QueryRow(ctx, `SELECT id FROM positions WHERE public_id = $1`, positionPublicID).Scan(&positionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", models.ErrPositionNotFound
+		}
+		r.logger.Errorf("Error occurred while retrieving position: %v", err)
+		return "", err
+	}
+	err = tx.QueryRow(ctx, `SELECT id FROM candidates WHERE public_id = $1`, candidatePublicID).Scan(&candidateID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", models.ErrUserNotFound
+		}
+		r.logger.Errorf("Error occurred while retrieving candidate: %v", err)
+		return "", err
+	}
+
+	var interviewID int
+	var interviewPublicID string
+	err = tx.QueryRow(ctx, `
+		INSERT INTO interviews (status)
+		VALUES ($1)
+		RETURNING id, public_id;
+	`, models.InterviewStatusPending).Scan(&interviewID, &interviewPublicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while creating interview: %v", err)
+		return "", err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO user_interviews (candidate_id, position_id, interview_id)
+		VALUES ($1, $2, $3);
+	`, candidateID, positionID, interviewID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while assigning interview: %v", err)
+		return "", err
+	}
+
+	var publicID string
+	err = tx.QueryRow(ctx, `
+		INSERT INTO invitations (interview_public_id, nonce, status, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING public_id;
+	`, interviewPublicID, nonce, models.InvitationStatusSent, expiresAt).Scan(&publicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while creating invitation: %v", err)
+		return "", err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Error occurred while committing invitation: %v", err)
+		return "", err
+	}
+	return publicID, nil
+}
+
+func (r *invitationRepository) GetInvitation(publicID string) (*models.Invitation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	invitation, err := scanInvitation(conn(ctx, r.db).QueryRow(ctx, selectInvitations+`WHERE inv.public_id = $1`, publicID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrInvitationNotFound
+		}
+		r.logger.Errorf("Error occurred while retrieving invitation: %v", err)
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (r *invitationRepository) GetPositionInvitations(positionPublicID string) ([]*models.Invitation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	result := make([]*models.Invitation, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, selectInvitations+`WHERE p.public_id = $1 ORDER BY inv.id DESC`, positionPublicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while retrieving invitations: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result = append(result, invitation)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *invitationRepository) ResendInvitation(publicID, nonce string, expiresAt time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		UPDATE invitations
+		SET nonce = $2, expires_at = $3, status = $4, sent_at = NOW(), reminded_at = NULL, resend_count = resend_count + 1
+		WHERE public_id = $1 AND status IN ('sent', 'opened', 'expired');
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, publicID, nonce, expiresAt, models.InvitationStatusSent)
+	if err != nil {
+		r.logger.Errorf("Error occurred while resending invitation: %v", err)
+		return false, err
+	}
+	return tag.RowsAffected() != 0, nil
+}
+
+// TransitionInvitation moves a live invitation whose token carries nonce
+// from one of the from statuses to status and stamps the matching time.
+// An empty nonce skips the token check.
+func (r *invitationRepository) TransitionInvitation(ctx context.Context, publicID, nonce string, from []string, to string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		UPDATE invitations
+		SET status = $4::text,
+			opened_at = CASE WHEN $4::text = 'opened' THEN NOW() ELSE opened_at END,
+			started_at = CASE WHEN $4::text = 'started' THEN NOW() ELSE started_at END,
+			revoked_at = CASE WHEN $4::text = 'revoked' THEN NOW() ELSE revoked_at END
+		WHERE public_id = $1 AND ($2::text = '' OR nonce = $2::text) AND status = ANY($3::text[])
+		AND ($4::text IN ('revoked', 'expired') OR expires_at > NOW());
+	`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, publicID, nonce, from, to)
+	if err != nil {
+		r.logger.Errorf("Error occurred while updating invitation status: %v", err)
+		return false, err
+	}
+	return tag.RowsAffected() != 0, nil
+}
+
+// ClaimInvitationReminders marks the live, not yet reminded invitations that
+// expire before expiresBefore as reminded and returns their public ids.
+func (r *invitationRepository) ClaimInvitationReminders(expiresBefore time.Time) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		UPDATE invitations
+		SET reminded_at = NOW()
+		WHERE status IN ('sent', 'opened') AND reminded_at IS NULL
+		AND expires_at > NOW() AND expires_at <= $1
+		RETURNING public_id;
+	`
+
+	result := make([]string, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, query, expiresBefore)
+	if err != nil {
+		r.logger.Errorf("Error occurred while claiming invitation reminders: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var publicID string
+		if err = rows.Scan(&publicID); err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result = append(result, publicID)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ReleaseInvitationReminder lets the invitation be reminded again, after
+// its reminder could not be sent.
+func (r *invitationRepository) ReleaseInvitationReminder(publicID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE invitations SET reminded_at = NULL WHERE public_id = $1;`, publicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while releasing invitation reminder: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ExpireInvitations expires the live invitations past their expiry together
+// with their interviews that were never started.
+func (r *invitationRepository) ExpireInvitations() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		WITH expired AS (
+			UPDATE invitations
+			SET status = 'expired'
+			WHERE status IN ('sent', 'opened') AND expires_at <= NOW()
+			RETURNING interview_public_id
+		), changed AS (
+			UPDATE interviews
+			SET status = $1
+			WHERE public_id IN (SELECT interview_public_id FROM expired) AND status = $2
+			RETURNING public_id, status
+		), events AS (
+			INSERT INTO outbox (aggregate_id, event, payload)
+			SELECT public_id, 'interview.' || status, jsonb_build_object('status', status)
+			FROM changed
+		)
+		SELECT COUNT(*) FROM expired;
+	`
+
+	var count int
+	err := conn(ctx, r.db).QueryRow(ctx, query, models.InterviewStatusExpired, models.InterviewStatusPending).Scan(&count)
+	if err != nil {
+		r.logger.Errorf("Error occurred while expiring invitations: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func scanInvitation(row pgx.Row) (*models.Invitation, error) {
+	invitation := &models.Invitation{}
+	err := row.Scan(&invitation.PublicID, &invitation.InterviewPublicID, &invitation.CandidatePublicID, &invitation.PositionPublicID,
+		&invitation.Status, &invitation.ExpiresAt, &invitation.SentAt, &invitation.OpenedAt, &invitation.StartedAt,
+		&invitation.RevokedAt, &invitation.ResendCount, &invitation.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+type keyPointRepository struct {
+	db     *pgxpool.Pool
+	cfg    *config.DBConf
+	logger *zap.SugaredLogger
+}
+
+func NewKeyPointRepository(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.SugaredLogger) KeyPointRepository {
+	return &keyPointRepository{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *keyPointRepository) GetKeyPoints(ctx context.Context, questionPublicIDs ...string) (map[string][]*models.KeyPoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT question_public_id, public_id, text, keywords, weight
+		FROM question_key_points
+		WHERE question_public_id = ANY($1::uuid[])
+		ORDER BY id;
+	`
+
+	result := make(map[string][]*models.KeyPoint)
+	rows, err := conn(ctx, r.db).Query(ctx, query, questionPublicIDs)
+	if err != nil {
+		r.logger.Errorf("Error occurred while retrieving key points: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var questionPublicID string
+		var keywords []byte
+		point := &models.KeyPoint{}
+		err = rows.Scan(&questionPublicID, &point.PublicID, &point.Text, &keywords, &point.Weight)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		err = json.Unmarshal(keywords, &point.Keywords)
+		if err != nil {
+			r.logger.Errorf("Error occurred while unmarshll: %v", err)
+			return nil, err
+		}
+		result[questionPublicID] = append(result[questionPublicID], point)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *keyPointRepository) SetKeyPoints(questionPublicID string, points []*models.KeyPoint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Error occurred while starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM questions WHERE public_id = $1)`, questionPublicID).Scan(&exists)
+	if err != nil {
+		r.logger.Errorf("Error occurred while checking question: %v", err)
+		return err
+	}
+	if !exists {
+		return models.ErrQuestionNotFound
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM question_key_points WHERE question_public_id = $1`, questionPublicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while deleting key points: %v", err)
+		return err
+	}
+
+	query := `
+		INSERT INTO question_key_points (question_public_id, text, keywords, weight)
+		VALUES ($1, $2, $3, $4)
+		RETURNING public_id;
+	`
+	for _, point := range points {
+		keywords, err := json.Marshal(point.Keywords)
+		if err != nil {
+			r.logger.Errorf("Failed to marshal keywords to JSON: %v", err)
+			return err
+		}
+		err = tx.QueryRow(ctx, query, questionPublicID, point.Text, keywords, point.Weight).Scan(&point.PublicID)
+		if err != nil {
+			r.logger.Errorf("Error occurred while adding key point: %v", err)
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Error occurred while committing key points: %v", err)
+		return err
+	}
+	return nil
+}
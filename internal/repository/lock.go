@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+// lockCheck is how often the session holding an advisory lock is checked.
+const lockCheck = 5 * time.Second
+
+// lockRepository takes each advisory lock on a connection of its own, kept
+// out of the pool until the lock is released.
+type lockRepository struct {
+	db     *pgxpool.Pool
+	cfg    *config.DBConf
+	logger *zap.SugaredLogger
+}
+
+func NewLockRepository(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.SugaredLogger) LockRepository {
+	return &lockRepository{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// TryWithAdvisoryLock runs fn while holding the session advisory lock for
+// name. It returns false without running fn when another caller, on this
+// or any other replica, holds the lock. The lock goes with its session, so
+// the context of fn is cancelled once the session is found gone.
+func (r *lockRepository) TryWithAdvisoryLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	key := advisoryKey(name)
+	session, err := r.lock(ctx, key)
+	if err != nil {
+		r.logger.Errorf("Error occurred while taking advisory lock %s: %v", name, err)
+		return false, err
+	}
+	if session == nil {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	watching := make(chan struct{})
+	go func() {
+		defer close(watching)
+		r.watchSession(ctx, cancel, session, name)
+	}()
+	defer func() {
+		cancel()
+		<-watching
+		if err := r.unlock(session, key); err != nil {
+			r.logger.Errorf("Error occurred while releasing advisory lock %s: %v", name, err)
+		}
+	}()
+
+	return true, fn(ctx)
+}
+
+// lock takes the advisory lock for key on a new session and returns the
+// session, or nil when the lock is held.
+func (r *lockRepository) lock(ctx context.Context, key int64) (*pgxpool.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	session, err := r.db.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var locked bool
+	if err := session.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil {
+		r.closeSession(session)
+		return nil, err
+	}
+	if !locked {
+		session.Release()
+		return nil, nil
+	}
+	return session, nil
+}
+
+// watchSession checks the session holding the lock for name until ctx is
+// done, and calls cancel once the session is gone, and the lock with it.
+func (r *lockRepository) watchSession(ctx context.Context, cancel context.CancelFunc, session *pgxpool.Conn, name string) {
+	ticker := time.NewTicker(lockCheck)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		pingCtx, pingCancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+		err := session.Ping(pingCtx)
+		pingCancel()
+		if err != nil && ctx.Err() == nil {
+			r.logger.Warnf("advisory lock %s was lost: %v", name, err)
+			cancel()
+			return
+		}
+	}
+}
+
+func (r *lockRepository) unlock(session *pgxpool.Conn, key int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	if _, err := session.Exec(ctx, `SELECT pg_advisory_unlock($1)`, key); err != nil {
+		r.closeSession(session)
+		return err
+	}
+	session.Release()
+	return nil
+}
+
+// closeSession drops a session that failed, and with it the lock it may
+// hold, so that it doesn't go back to the pool with it.
+func (r *lockRepository) closeSession(session *pgxpool.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+	session.Conn().Close(ctx)
+	session.Release()
+}
+
+func advisoryKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
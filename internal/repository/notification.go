@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+type notificationRepository struct {
+	db     *pgxpool.Pool
+	cfg    *config.DBConf
+	logger *zap.SugaredLogger
+}
+
+func NewNotificationRepository(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.SugaredLogger) NotificationRepository {
+	return &notificationRepository{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *notificationRepository) GetNotificationContext(interviewPublicID string) (*models.NotificationContext, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT i.public_id,
+			TRIM(cu.first_name || ' ' || COALESCE(cu.last_name, '')), COALESCE(cu.email, ''),
+			TRIM(COALESCE(ru.first_name, '') || ' ' || COALESCE(ru.last_name, '')), COALESCE(ru.email, ''),
+			COALESCE(p.name, ''), COALESCE(co.name, ''), COALESCE(co.logo, '')
+		FROM interviews AS i
+		JOIN user_interviews ui ON ui.interview_id = i.id
+		JOIN candidates c ON c.id = ui.candidate_id
+		JOIN users cu ON cu.public_id = c.public_id
+		JOIN positions p ON p.id = ui.position_id
+		LEFT JOIN recruiters r ON r.public_id = p.recruiter_public_id
+		LEFT JOIN users ru ON ru.public_id = r.public_id
+		LEFT JOIN companies co ON co.public_id = r.company_public_id
+		WHERE i.public_id = $1
+		LIMIT 1;
+	`
+
+	n := &models.NotificationContext{}
+	err := conn(ctx, r.db).QueryRow(ctx, query, interviewPublicID).Scan(&n.InterviewPublicID, &n.CandidateName, &n.CandidateEmail,
+		&n.RecruiterName, &n.RecruiterEmail, &n.PositionName, &n.CompanyName, &n.CompanyLogo)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrInterviewNotFound
+		}
+		r.logger.Errorf("Error occurred while retrieving notification context: %v", err)
+		return nil, err
+	}
+	return n, nil
+}
+
+func (r *notificationRepository) AddDelivery(delivery *models.EmailDelivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		INSERT INTO email_deliveries (interview_public_id, event, recipient, subject, status, error)
+		VALUES (NULLIF($1, '')::uuid, $2, $3, $4, $5, NULLIF($6, ''));
+	`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, delivery.InterviewPublicID, delivery.Event, delivery.Recipient, delivery.Subject, delivery.Status, delivery.Error)
+	if err != nil {
+		r.logger.Errorf("Error occurred while recording email delivery: %v", err)
+		return err
+	}
+	return nil
+}
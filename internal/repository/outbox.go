@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+// insertStatusEvents records an "interview.<status>" event for every row of
+// the preceding "changed" CTE, which must return public_id and status. The
+// event keeps the score and override the interview has at the change.
+const insertStatusEvents = `
+	INSERT INTO outbox (aggregate_id, event, payload)
+	SELECT c.public_id, 'interview.' || c.status, jsonb_build_object(
+		'status', c.status,
+		'score', i.results->'score',
+		'override', i.results->'override'
+	)
+	FROM changed AS c
+	JOIN interviews AS i ON i.public_id = c.public_id;
+`
+
+type outboxRepository struct {
+	db     *pgxpool.Pool
+	cfg    *config.DBConf
+	logger *zap.SugaredLogger
+}
+
+func NewOutboxRepository(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.SugaredLogger) OutboxRepository {
+	return &outboxRepository{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// GetPendingEvents returns the oldest unpublished events in the order they
+// were recorded.
+func (r *outboxRepository) GetPendingEvents(limit int) ([]*models.OutboxEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT id, aggregate_id, event, payload, attempts, handled, created_at
+		FROM outbox
+		WHERE published_at IS NULL
+		ORDER BY id
+		LIMIT $1;
+	`
+
+	result := make([]*models.OutboxEvent, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, query, limit)
+	if err != nil {
+		r.logger.Errorf("Error occurred while retrieving outbox events: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e := &models.OutboxEvent{}
+		err = rows.Scan(&e.ID, &e.AggregateID, &e.Event, &e.Payload, &e.Attempts, &e.Handled, &e.CreatedAt)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result = append(result, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// MarkEventHandled records that subscriber has processed the event, so a
+// retry of the event skips it.
+func (r *outboxRepository) MarkEventHandled(id int64, subscriber string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE outbox SET handled = array_append(handled, $2) WHERE id = $1`, id, subscriber)
+	if err != nil {
+		r.logger.Errorf("Error occurred while updating outbox event: %v", err)
+		return err
+	}
+	return nil
+}
+
+// MarkEventPublished removes the event from the queue. A non-empty lastError
+// means the relay gave up on it.
+func (r *outboxRepository) MarkEventPublished(id int64, lastError string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).Exec(ctx, `
+		UPDATE outbox SET published_at = NOW(), last_error = COALESCE(NULLIF($2, ''), last_error)
+		WHERE id = $1;
+	`, id, lastError)
+	if err != nil {
+		r.logger.Errorf("Error occurred while updating outbox event: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *outboxRepository) MarkEventFailed(id int64, lastError string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE outbox SET attempts = attempts + 1, last_error = $2 WHERE id = $1`, id, lastError)
+	if err != nil {
+		r.logger.Errorf("Error occurred while updating outbox event: %v", err)
+		return err
+	}
+	return nil
+}
+
+// addOutboxEvent records event in tx, the transaction of the write that
+// caused it.
+func addOutboxEvent(ctx context.Context, tx pgx.Tx, aggregateID, event string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO outbox (aggregate_id, event, payload)
+		VALUES ($1, $2, $3::jsonb);
+	`, aggregateID, event, data)
+	return err
+}
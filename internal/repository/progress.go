@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+type progressRepository struct {
+	db     *pgxpool.Pool
+	cfg    *config.DBConf
+	logger *zap.SugaredLogger
+}
+
+func NewProgressRepository(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.SugaredLogger) ProgressRepository {
+	return &progressRepository{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// NotifyProgress broadcasts event to the listeners of every replica.
+func (r *progressRepository) NotifyProgress(ctx context.Context, event *models.ProgressEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err = conn(ctx, r.db).Exec(ctx, `SELECT pg_notify($1, $2)`, models.ProgressChannel, string(payload)); err != nil {
+		r.logger.Errorf("Error occurred while notifying progress: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ListenProgress calls fn with every progress event until ctx is done or
+// the connection fails. It holds one connection of the pool meanwhile.
+func (r *progressRepository) ListenProgress(ctx context.Context, fn func(event *models.ProgressEvent)) error {
+	conn, err := r.db.Acquire(ctx)
+	if err != nil {
+		r.logger.Errorf("Error occurred while acquiring connection: %v", err)
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+		defer cancel()
+		conn.Exec(ctx, `UNLISTEN *`)
+		conn.Release()
+	}()
+
+	if _, err = conn.Exec(ctx, `LISTEN `+models.ProgressChannel); err != nil {
+		r.logger.Errorf("Error occurred while listening for progress: %v", err)
+		return err
+	}
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		event := &models.ProgressEvent{}
+		if err = json.Unmarshal([]byte(notification.Payload), event); err != nil {
+			r.logger.Errorf("Error occurred while decoding progress event: %v", err)
+			continue
+		}
+		fn(event)
+	}
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/Zhiyenbek/sp-interview-main-service/config"
 	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
 	"github.com/jackc/pgx/v4/pgxpool"
@@ -8,18 +11,150 @@ import (
 )
 
 type InterviewRepository interface {
-	GetInterviewByPublicID(publicID string) (*models.InterviewResults, error)
-	PutInterview(interview *models.InterviewResults) error
-	AddVideoToQuestion(questionPublicID, interviewPublicID, video string) error
-	GetAllInterviews() ([]*models.InterviewResults, error)
-	GetInterview(publicID string) (*models.InterviewResults, error)
+	GetInterviewByPublicID(ctx context.Context, publicID string) (*models.InterviewResults, error)
+	PutInterview(ctx context.Context, interview *models.InterviewResults) error
+	AddVideoToQuestion(ctx context.Context, questionPublicID, interviewPublicID, video string) error
+	GetAllInterviews(ctx context.Context) ([]*models.InterviewResults, error)
+	GetInterview(ctx context.Context, publicID string) (*models.InterviewResults, error)
+	GetSession(ctx context.Context, publicID string) (*models.InterviewSession, error)
+	GetInterviewCompany(ctx context.Context, publicID string) (*models.CompanyPlan, error)
+	GetSessionQuestions(ctx context.Context, publicID string) ([]*models.SessionQuestion, error)
+	StartInterview(ctx context.Context, publicID string, deadline time.Time) (bool, error)
+	TransitionInterview(ctx context.Context, publicID string, from []string, to string) (bool, error)
+	StartQuestion(ctx context.Context, interviewPublicID, questionPublicID string, deadline time.Time) error
+	AnswerQuestion(ctx context.Context, interviewPublicID, questionPublicID, video string) error
+	ClaimDeadlineReminders(ctx context.Context, deadlineBefore time.Time) ([]*models.InterviewSession, error)
+	ReleaseDeadlineReminder(ctx context.Context, publicID string) error
+	SubmitOverdueInterviews(ctx context.Context, deadlineBefore time.Time) ([]string, error)
+	ExpireInterviews(ctx context.Context, deadlineBefore time.Time) (int, error)
+	AddResultVersion(ctx context.Context, publicID, source string, result *models.Result) (int, error)
+	GetResultVersions(ctx context.Context, publicID string) ([]*models.ResultVersion, error)
+}
+
+type TranscriptRepository interface {
+	SaveTranscripts(ctx context.Context, interviewPublicID string, transcripts []*models.Transcript) error
+	SearchTranscripts(companyPublicID, language string, args *models.SearchArgs) (*models.TranscriptSearchResult, error)
+	GetPositionTranscripts(ctx context.Context, interviewPublicID string, questionPublicIDs []string) ([]*models.Transcript, error)
+}
+
+type KeyPointRepository interface {
+	GetKeyPoints(ctx context.Context, questionPublicIDs ...string) (map[string][]*models.KeyPoint, error)
+	SetKeyPoints(questionPublicID string, points []*models.KeyPoint) error
+}
+
+type SimilarityRepository interface {
+	ReplaceSimilarities(ctx context.Context, interviewPublicID string, similarities []*models.AnswerSimilarity) error
+	GetPositionSimilarities(positionPublicID string) ([]*models.AnswerSimilarity, error)
+}
+
+type IntegrityRepository interface {
+	AddIntegrityEvents(interviewPublicID string, events []*models.IntegrityEvent) error
+	ReplaceAnalyzerEvents(ctx context.Context, interviewPublicID string, questionPublicIDs []string, events []*models.IntegrityEvent) error
+	GetIntegrityCounts(ctx context.Context, interviewPublicIDs ...string) (map[string][]*models.IntegrityCount, error)
+}
+
+type InvitationRepository interface {
+	CreateInvitation(positionPublicID, candidatePublicID, nonce string, expiresAt time.Time) (string, error)
+	GetInvitation(publicID string) (*models.Invitation, error)
+	GetPositionInvitations(positionPublicID string) ([]*models.Invitation, error)
+	ResendInvitation(publicID, nonce string, expiresAt time.Time) (bool, error)
+	TransitionInvitation(ctx context.Context, publicID, nonce string, from []string, to string) (bool, error)
+	ClaimInvitationReminders(expiresBefore time.Time) ([]string, error)
+	ReleaseInvitationReminder(publicID string) error
+	ExpireInvitations() (int, error)
+}
+
+type NotificationRepository interface {
+	GetNotificationContext(interviewPublicID string) (*models.NotificationContext, error)
+	AddDelivery(delivery *models.EmailDelivery) error
+}
+
+type WebhookRepository interface {
+	CreateWebhookEndpoint(endpoint *models.WebhookEndpoint) error
+	GetCompanyWebhookEndpoints(companyPublicID string) ([]*models.WebhookEndpoint, error)
+	DeleteWebhookEndpoint(publicID string) error
+	EnqueueWebhookDeliveries(event, interviewPublicID string, payload []byte) ([]string, error)
+	ClaimWebhookDeliveries(publicIDs []string, limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	RecordWebhookAttempt(delivery *models.WebhookDelivery, attempt *models.WebhookAttempt) error
+	GetWebhookDeliveries(endpointPublicID string, args *models.SearchArgs) ([]*models.WebhookDelivery, error)
+	GetWebhookDelivery(publicID string) (*models.WebhookDelivery, error)
+	ReplayWebhookDelivery(publicID string) (string, error)
 }
+
+type OutboxRepository interface {
+	GetPendingEvents(limit int) ([]*models.OutboxEvent, error)
+	MarkEventHandled(id int64, subscriber string) error
+	MarkEventPublished(id int64, lastError string) error
+	MarkEventFailed(id int64, lastError string) error
+}
+
+type ProgressRepository interface {
+	NotifyProgress(ctx context.Context, event *models.ProgressEvent) error
+	ListenProgress(ctx context.Context, fn func(event *models.ProgressEvent)) error
+}
+
+type AnalysisRepository interface {
+	SaveQuestionAnalysis(ctx context.Context, analysis *models.QuestionAnalysis) error
+	GetQuestionAnalyses(ctx context.Context, interviewPublicID string) ([]*models.QuestionAnalysis, error)
+}
+
+type DeadLetterRepository interface {
+	RecordFailedAnalysis(ctx context.Context, failure *models.FailedAnalysis) error
+	ResolveFailedAnalysis(ctx context.Context, interviewPublicID string) error
+	GetFailedAnalyses(status string, args *models.SearchArgs) ([]*models.FailedAnalysis, error)
+	GetFailedAnalysis(publicID string) (*models.FailedAnalysis, error)
+	ClaimFailedAnalyses(publicIDs []string) ([]*models.FailedAnalysis, error)
+	ReleaseFailedAnalysis(ctx context.Context, failure *models.FailedAnalysis) error
+	DiscardFailedAnalyses(publicIDs []string) (int, error)
+}
+
+type IdempotencyRepository interface {
+	GetAnalysisRequest(ctx context.Context, interviewPublicID, key string) (*models.AnalysisRequest, error)
+	SetAnalysisRequest(ctx context.Context, interviewPublicID, key, status string) error
+}
+
+type LockRepository interface {
+	TryWithAdvisoryLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error)
+}
+
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type Repository struct {
 	InterviewRepository
+	TranscriptRepository
+	KeyPointRepository
+	SimilarityRepository
+	IntegrityRepository
+	InvitationRepository
+	NotificationRepository
+	WebhookRepository
+	OutboxRepository
+	ProgressRepository
+	AnalysisRepository
+	DeadLetterRepository
+	IdempotencyRepository
+	LockRepository
+	TxManager
 }
 
 func New(db *pgxpool.Pool, cfg *config.Configs, log *zap.SugaredLogger) *Repository {
 	return &Repository{
-		InterviewRepository: NewInterviewRepository(db, cfg.DB, log),
+		InterviewRepository:    NewInterviewRepository(db, cfg.DB, log),
+		TranscriptRepository:   NewTranscriptRepository(db, cfg.DB, log),
+		KeyPointRepository:     NewKeyPointRepository(db, cfg.DB, log),
+		SimilarityRepository:   NewSimilarityRepository(db, cfg.DB, log),
+		IntegrityRepository:    NewIntegrityRepository(db, cfg.DB, log),
+		InvitationRepository:   NewInvitationRepository(db, cfg.DB, log),
+		NotificationRepository: NewNotificationRepository(db, cfg.DB, log),
+		WebhookRepository:      NewWebhookRepository(db, cfg.DB, log),
+		OutboxRepository:       NewOutboxRepository(db, cfg.DB, log),
+		ProgressRepository:     NewProgressRepository(db, cfg.DB, log),
+		AnalysisRepository:     NewAnalysisRepository(db, cfg.DB, log),
+		DeadLetterRepository:   NewDeadLetterRepository(db, cfg.DB, log),
+		IdempotencyRepository:  NewIdempotencyRepository(db, cfg.DB, log),
+		LockRepository:         NewLockRepository(db, cfg.DB, log),
+		TxManager:              NewTxManager(db, cfg.DB, log),
 	}
 }
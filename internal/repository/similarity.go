@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+type similarityRepository struct {
+	db     *pgxpool.Pool
+	cfg    *config.DBConf
+	logger *zap.SugaredLogger
+}
+
+func NewSimilarityRepository(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.SugaredLogger) SimilarityRepository {
+	return &similarityRepository{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *similarityRepository) ReplaceSimilarities(ctx context.Context, interviewPublicID string, similarities []*models.AnswerSimilarity) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Error occurred while starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM answer_similarities
+		WHERE interview_public_id = $1 OR other_interview_public_id = $1;
+	`, interviewPublicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while deleting answer similarities: %v", err)
+		return err
+	}
+
+	query := `
+		INSERT INTO answer_similarities (question_public_id, interview_public_id, other_interview_public_id, score, fragments)
+		VALUES ($1, $2, $3, $4, $5);
+	`
+	for _, s := range similarities {
+		fragments, err := json.Marshal(s.Fragments)
+		if err != nil {
+			r.logger.Errorf("Failed to marshal fragments to JSON: %v", err)
+			return err
+		}
+		_, err = tx.Exec(ctx, query, s.QuestionPublicID, s.InterviewPublicID, s.OtherInterviewPublicID, s.Score, fragments)
+		if err != nil {
+			r.logger.Errorf("Error occurred while adding answer similarity: %v", err)
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Error occurred while committing answer similarities: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *similarityRepository) GetPositionSimilarities(positionPublicID string) ([]*models.AnswerSimilarity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT DISTINCT s.id, s.question_public_id, COALESCE(q.name, ''), s.interview_public_id, s.other_interview_public_id, s.score, s.fragments, s.created_at
+		FROM answer_similarities AS s
+		JOIN interviews i ON i.public_id = s.interview_public_id
+		JOIN user_interviews ui ON ui.interview_id = i.id
+		JOIN positions p ON p.id = ui.position_id
+		LEFT JOIN questions q ON q.public_id = s.question_public_id
+		WHERE p.public_id = $1
+		ORDER BY s.score DESC, s.id;
+	`
+
+	result := make([]*models.AnswerSimilarity, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, query, positionPublicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while retrieving answer similarities: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var fragments []byte
+		s := &models.AnswerSimilarity{}
+		err = rows.Scan(&id, &s.QuestionPublicID, &s.Question, &s.InterviewPublicID, &s.OtherInterviewPublicID, &s.Score, &fragments, &s.CreatedAt)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		err = json.Unmarshal(fragments, &s.Fragments)
+		if err != nil {
+			r.logger.Errorf("Error occurred while unmarshll: %v", err)
+			return nil, err
+		}
+		result = append(result, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
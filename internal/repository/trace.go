@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/Zhiyenbek/sp-interview-main-service/internal/repository")
+
+// tracedQuerier runs every statement in a span of its own. Transactions
+// begun on it are traced as well.
+type tracedQuerier struct {
+	q querier
+}
+
+func (t tracedQuerier) Begin(ctx context.Context) (pgx.Tx, error) {
+	ctx, span := startQuery(ctx, "BEGIN")
+	tx, err := t.q.Begin(ctx)
+	endQuery(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return tracedTx{Tx: tx}, nil
+}
+
+func (t tracedQuerier) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	ctx, span := startQuery(ctx, sql)
+	tag, err := t.q.Exec(ctx, sql, args...)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("db.rows_affected", tag.RowsAffected()))
+	}
+	endQuery(span, err)
+	return tag, err
+}
+
+func (t tracedQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	ctx, span := startQuery(ctx, sql)
+	rows, err := t.q.Query(ctx, sql, args...)
+	if err != nil {
+		endQuery(span, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func (t tracedQuerier) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	ctx, span := startQuery(ctx, sql)
+	return tracedRow{row: t.q.QueryRow(ctx, sql, args...), span: span}
+}
+
+// tracedTx traces the statements run on a transaction.
+type tracedTx struct {
+	pgx.Tx
+}
+
+func (t tracedTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return tracedQuerier{q: t.Tx}.Begin(ctx)
+}
+
+func (t tracedTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return tracedQuerier{q: t.Tx}.Exec(ctx, sql, args...)
+}
+
+func (t tracedTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return tracedQuerier{q: t.Tx}.Query(ctx, sql, args...)
+}
+
+func (t tracedTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return tracedQuerier{q: t.Tx}.QueryRow(ctx, sql, args...)
+}
+
+func (t tracedTx) Commit(ctx context.Context) error {
+	ctx, span := startQuery(ctx, "COMMIT")
+	err := t.Tx.Commit(ctx)
+	endQuery(span, err)
+	return err
+}
+
+// tracedRow ends the span of the query once the row is scanned.
+type tracedRow struct {
+	row  pgx.Row
+	span trace.Span
+}
+
+func (r tracedRow) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+	if err == pgx.ErrNoRows {
+		endQuery(r.span, nil)
+	} else {
+		endQuery(r.span, err)
+	}
+	return err
+}
+
+// tracedRows ends the span of the query once the rows are read or closed.
+type tracedRows struct {
+	pgx.Rows
+	span trace.Span
+	once sync.Once
+}
+
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.end()
+	return false
+}
+
+func (r *tracedRows) Close() {
+	r.Rows.Close()
+	r.end()
+}
+
+func (r *tracedRows) end() {
+	r.once.Do(func() { endQuery(r.span, r.Rows.Err()) })
+}
+
+func startQuery(ctx context.Context, sql string) (context.Context, trace.Span) {
+	sql = strings.TrimSpace(sql)
+	operation := sql
+	if i := strings.IndexAny(sql, " \t\n"); i >= 0 {
+		operation = sql[:i]
+	}
+	operation = strings.ToUpper(operation)
+	return tracer.Start(ctx, "db "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperation(operation),
+			semconv.DBStatement(sql),
+		),
+	)
+}
+
+func endQuery(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
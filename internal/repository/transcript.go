@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+type transcriptRepository struct {
+	db     *pgxpool.Pool
+	cfg    *config.DBConf
+	logger *zap.SugaredLogger
+}
+
+func NewTranscriptRepository(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.SugaredLogger) TranscriptRepository {
+	return &transcriptRepository{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *transcriptRepository) SaveTranscripts(ctx context.Context, interviewPublicID string, transcripts []*models.Transcript) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		INSERT INTO transcripts (interview_public_id, question_public_id, language, answer, tsv)
+		VALUES ($1, $2, $3::text::regconfig, $4, to_tsvector($3::text::regconfig, $4))
+		ON CONFLICT (interview_public_id, question_public_id)
+		DO UPDATE SET language = EXCLUDED.language, answer = EXCLUDED.answer, tsv = EXCLUDED.tsv;
+	`
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Error occurred while starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, t := range transcripts {
+		_, err = tx.Exec(ctx, query, interviewPublicID, t.QuestionPublicID, t.Language, t.Answer)
+		if err != nil {
+			r.logger.Errorf("Error occurred while saving transcript: %v", err)
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Error occurred while committing transcripts: %v", err)
+		return err
+	}
+	return nil
+}
+
+// SearchTranscripts escapes the answers before highlighting the matches,
+// as the snippets are rendered as HTML.
+func (r *transcriptRepository) SearchTranscripts(companyPublicID, language string, args *models.SearchArgs) (*models.TranscriptSearchResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT t.interview_public_id, t.question_public_id, COALESCE(q.name, ''), c.public_id,
+			ts_headline(t.language,
+				replace(replace(replace(replace(replace(t.answer, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+				query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=3, FragmentDelimiter=" ... "'),
+			ts_rank(t.tsv, query) AS rank,
+			COUNT(*) OVER()
+		FROM transcripts AS t
+		CROSS JOIN websearch_to_tsquery($2::text::regconfig, $3) AS query
+		JOIN interviews i ON i.public_id = t.interview_public_id
+		JOIN user_interviews ui ON ui.interview_id = i.id
+		JOIN candidates c ON c.id = ui.candidate_id
+		JOIN positions p ON p.id = ui.position_id
+		JOIN recruiters r ON r.public_id = p.recruiter_public_id
+		LEFT JOIN questions q ON q.public_id = t.question_public_id
+		WHERE r.company_public_id = $1 AND t.language = $2::text::regconfig AND t.tsv @@ query
+		ORDER BY rank DESC, t.id
+		LIMIT $4 OFFSET $5;
+	`
+
+	result := &models.TranscriptSearchResult{
+		Matches: make([]*models.TranscriptMatch, 0),
+	}
+	rows, err := conn(ctx, r.db).Query(ctx, query, companyPublicID, language, args.Search, args.PageSize, (args.PageNum-1)*args.PageSize)
+	if err != nil {
+		r.logger.Errorf("Error occurred while searching transcripts: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		match := &models.TranscriptMatch{}
+		err = rows.Scan(&match.InterviewPublicID, &match.QuestionPublicID, &match.Question, &match.CandidatePublicID, &match.Snippet, &match.Rank, &result.Count)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result.Matches = append(result.Matches, match)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *transcriptRepository) GetPositionTranscripts(ctx context.Context, interviewPublicID string, questionPublicIDs []string) ([]*models.Transcript, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT DISTINCT t.interview_public_id, t.question_public_id, t.language::text, t.answer
+		FROM transcripts AS t
+		JOIN interviews i ON i.public_id = t.interview_public_id
+		JOIN user_interviews ui ON ui.interview_id = i.id
+		WHERE t.interview_public_id <> $1
+		AND t.question_public_id = ANY($2::uuid[])
+		AND ui.position_id IN (
+			SELECT ui2.position_id FROM user_interviews ui2
+			JOIN interviews i2 ON i2.id = ui2.interview_id
+			WHERE i2.public_id = $1
+		);
+	`
+
+	result := make([]*models.Transcript, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, query, interviewPublicID, questionPublicIDs)
+	if err != nil {
+		r.logger.Errorf("Error occurred while retrieving position transcripts: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t := &models.Transcript{}
+		err = rows.Scan(&t.InterviewPublicID, &t.QuestionPublicID, &t.Language, &t.Answer)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result = append(result, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
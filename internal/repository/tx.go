@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+// querier runs the statements of a repository: on the pool, or on the
+// transaction of the unit of work in progress. Begin on a transaction
+// starts a savepoint.
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+type txKey struct{}
+
+// conn returns the transaction of the unit of work running in ctx, or db
+// outside of one.
+func conn(ctx context.Context, db *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tracedTx{Tx: tx}
+	}
+	return tracedQuerier{q: db}
+}
+
+type txManager struct {
+	db     *pgxpool.Pool
+	cfg    *config.DBConf
+	logger *zap.SugaredLogger
+}
+
+func NewTxManager(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.SugaredLogger) TxManager {
+	return &txManager{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// WithinTx runs fn in a serializable transaction that the repository calls
+// made with the ctx passed to fn take part in. It commits when fn returns
+// nil and rolls back otherwise. On a serialization failure or a deadlock
+// the whole of fn runs again, up to cfg.Retries times, so fn must not have
+// effects outside the database. Within a unit of work fn joins it.
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := m.run(ctx, fn)
+		if err == nil || !retryable(err) || attempt >= m.cfg.Retries {
+			return err
+		}
+		m.logger.Warnf("transaction failed on attempt %d, retrying: %v", attempt+1, err)
+
+		timer := time.NewTimer(time.Duration(attempt+1) * 10 * time.Millisecond)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+func (m *txManager) run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	ctx, span := tracer.Start(ctx, "db transaction")
+	defer func() { endQuery(span, err) }()
+
+	tx, err := m.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		m.logger.Errorf("Error occurred while starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		m.logger.Errorf("Error occurred while committing transaction: %v", err)
+		return err
+	}
+	return nil
+}
+
+// retryable tells whether err is a serialization failure or a deadlock,
+// after which the transaction may succeed when run again.
+func retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
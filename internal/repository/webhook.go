@@ -0,0 +1,359 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+const selectWebhookDeliveries = `
+	SELECT d.public_id, d.endpoint_public_id, d.event, d.interview_public_id, d.payload, d.status, d.attempts,
+		CASE WHEN d.status = 'pending' THEN d.next_attempt_at END,
+		d.last_response_code, COALESCE(d.last_error, ''), d.delivered_at, d.replay_of, d.created_at
+	FROM webhook_deliveries AS d
+`
+
+type webhookRepository struct {
+	db     *pgxpool.Pool
+	cfg    *config.DBConf
+	logger *zap.SugaredLogger
+}
+
+func NewWebhookRepository(db *pgxpool.Pool, cfg *config.DBConf, logger *zap.SugaredLogger) WebhookRepository {
+	return &webhookRepository{
+		db:     db,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *webhookRepository) CreateWebhookEndpoint(endpoint *models.WebhookEndpoint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		INSERT INTO webhook_endpoints (company_public_id, url, secret, events)
+		SELECT public_id, $2, $3, $4 FROM companies WHERE public_id = $1
+		RETURNING public_id, created_at;
+	`
+
+	err := conn(ctx, r.db).QueryRow(ctx, query, endpoint.CompanyPublicID, endpoint.URL, endpoint.Secret, endpoint.Events).
+		Scan(&endpoint.PublicID, &endpoint.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ErrCompanyNotFound
+		}
+		r.logger.Errorf("Error occurred while creating webhook endpoint: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetCompanyWebhookEndpoints(companyPublicID string) ([]*models.WebhookEndpoint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		SELECT public_id, company_public_id, url, events, created_at
+		FROM webhook_endpoints
+		WHERE company_public_id = $1 AND active
+		ORDER BY id;
+	`
+
+	result := make([]*models.WebhookEndpoint, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, query, companyPublicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while retrieving webhook endpoints: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		endpoint := &models.WebhookEndpoint{}
+		err = rows.Scan(&endpoint.PublicID, &endpoint.CompanyPublicID, &endpoint.URL, &endpoint.Events, &endpoint.CreatedAt)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result = append(result, endpoint)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteWebhookEndpoint deactivates the endpoint. Its delivery log is kept.
+func (r *webhookRepository) DeleteWebhookEndpoint(publicID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE webhook_endpoints SET active = FALSE WHERE public_id = $1 AND active`, publicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while deleting webhook endpoint: %v", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// EnqueueWebhookDeliveries creates a pending delivery of payload for every
+// active endpoint of the interview's company subscribed to event.
+func (r *webhookRepository) EnqueueWebhookDeliveries(event, interviewPublicID string, payload []byte) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_public_id, event, interview_public_id, payload)
+		SELECT DISTINCT e.public_id, $1, i.public_id, $3::jsonb
+		FROM interviews AS i
+		JOIN user_interviews ui ON ui.interview_id = i.id
+		JOIN positions p ON p.id = ui.position_id
+		JOIN recruiters r ON r.public_id = p.recruiter_public_id
+		JOIN webhook_endpoints e ON e.company_public_id = r.company_public_id
+		WHERE i.public_id = $2 AND e.active AND $1 = ANY(e.events)
+		RETURNING public_id;
+	`
+
+	result := make([]string, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, query, event, interviewPublicID, payload)
+	if err != nil {
+		r.logger.Errorf("Error occurred while enqueueing webhook deliveries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var publicID string
+		if err = rows.Scan(&publicID); err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result = append(result, publicID)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ClaimWebhookDeliveries leases up to limit due deliveries, or the due ones
+// among publicIDs when given, by pushing their next attempt past lease so
+// that concurrent senders skip them.
+func (r *webhookRepository) ClaimWebhookDeliveries(publicIDs []string, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		UPDATE webhook_deliveries AS d
+		SET next_attempt_at = NOW() + $3 * INTERVAL '1 millisecond'
+		FROM webhook_endpoints AS e
+		WHERE e.public_id = d.endpoint_public_id AND d.id IN (
+			SELECT d2.id FROM webhook_deliveries AS d2
+			JOIN webhook_endpoints e2 ON e2.public_id = d2.endpoint_public_id
+			WHERE d2.status = 'pending' AND d2.next_attempt_at <= NOW() AND e2.active
+			AND ($1::uuid[] IS NULL OR d2.public_id = ANY($1::uuid[]))
+			ORDER BY d2.next_attempt_at, d2.id
+			LIMIT $2
+			FOR UPDATE OF d2 SKIP LOCKED
+		)
+		RETURNING d.public_id, d.endpoint_public_id, d.event, d.interview_public_id, d.payload, d.attempts, e.url, e.secret;
+	`
+
+	result := make([]*models.WebhookDelivery, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, query, publicIDs, limit, lease.Milliseconds())
+	if err != nil {
+		r.logger.Errorf("Error occurred while claiming webhook deliveries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d := &models.WebhookDelivery{Status: models.WebhookDeliveryPending}
+		err = rows.Scan(&d.PublicID, &d.EndpointPublicID, &d.Event, &d.InterviewPublicID, &d.Payload, &d.Attempts, &d.URL, &d.Secret)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result = append(result, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RecordWebhookAttempt logs the attempt and stores the resulting state of
+// the delivery.
+func (r *webhookRepository) RecordWebhookAttempt(delivery *models.WebhookDelivery, attempt *models.WebhookAttempt) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		r.logger.Errorf("Error occurred while starting transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webhook_attempts (delivery_public_id, attempt, response_code, error, duration_ms)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5);
+	`, delivery.PublicID, attempt.Attempt, attempt.ResponseCode, attempt.Error, attempt.DurationMs)
+	if err != nil {
+		r.logger.Errorf("Error occurred while saving webhook attempt: %v", err)
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = COALESCE($4, next_attempt_at),
+			last_response_code = $5, last_error = NULLIF($6, ''), delivered_at = $7
+		WHERE public_id = $1;
+	`, delivery.PublicID, delivery.Status, delivery.Attempts, delivery.NextAttemptAt,
+		delivery.LastResponseCode, delivery.LastError, delivery.DeliveredAt)
+	if err != nil {
+		r.logger.Errorf("Error occurred while updating webhook delivery: %v", err)
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Errorf("Error occurred while committing webhook attempt: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetWebhookDeliveries(endpointPublicID string, args *models.SearchArgs) ([]*models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := selectWebhookDeliveries + `
+		WHERE d.endpoint_public_id = $1
+		ORDER BY d.id DESC
+		LIMIT $2 OFFSET $3;
+	`
+
+	result := make([]*models.WebhookDelivery, 0)
+	rows, err := conn(ctx, r.db).Query(ctx, query, endpointPublicID, args.PageSize, (args.PageNum-1)*args.PageSize)
+	if err != nil {
+		r.logger.Errorf("Error occurred while retrieving webhook deliveries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		result = append(result, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetWebhookDelivery returns the delivery together with its attempts.
+func (r *webhookRepository) GetWebhookDelivery(publicID string) (*models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	d, err := scanWebhookDelivery(conn(ctx, r.db).QueryRow(ctx, selectWebhookDeliveries+`WHERE d.public_id = $1`, publicID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrDeliveryNotFound
+		}
+		r.logger.Errorf("Error occurred while retrieving webhook delivery: %v", err)
+		return nil, err
+	}
+
+	query := `
+		SELECT attempt, response_code, COALESCE(error, ''), duration_ms, created_at
+		FROM webhook_attempts
+		WHERE delivery_public_id = $1
+		ORDER BY attempt;
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, publicID)
+	if err != nil {
+		r.logger.Errorf("Error occurred while retrieving webhook attempts: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	d.Log = make([]*models.WebhookAttempt, 0)
+	for rows.Next() {
+		a := &models.WebhookAttempt{}
+		if err = rows.Scan(&a.Attempt, &a.ResponseCode, &a.Error, &a.DurationMs, &a.CreatedAt); err != nil {
+			r.logger.Errorf("Error occurred while scanning rows: %v", err)
+			return nil, err
+		}
+		d.Log = append(d.Log, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Errorf("Error occurred while iterating rows: %v", err)
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// ReplayWebhookDelivery queues a copy of the delivery so that the original
+// log stays untouched.
+func (r *webhookRepository) ReplayWebhookDelivery(publicID string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TimeOut)
+	defer cancel()
+
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_public_id, event, interview_public_id, payload, replay_of)
+		SELECT endpoint_public_id, event, interview_public_id, payload, public_id
+		FROM webhook_deliveries
+		WHERE public_id = $1
+		RETURNING public_id;
+	`
+
+	var replayID string
+	err := conn(ctx, r.db).QueryRow(ctx, query, publicID).Scan(&replayID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", models.ErrDeliveryNotFound
+		}
+		r.logger.Errorf("Error occurred while replaying webhook delivery: %v", err)
+		return "", err
+	}
+	return replayID, nil
+}
+
+func scanWebhookDelivery(row pgx.Row) (*models.WebhookDelivery, error) {
+	d := &models.WebhookDelivery{}
+	err := row.Scan(&d.PublicID, &d.EndpointPublicID, &d.Event, &d.InterviewPublicID, &d.Payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastResponseCode, &d.LastError, &d.DeliveredAt, &d.ReplayOf, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
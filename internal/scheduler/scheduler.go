@@ -0,0 +1,140 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+// JobFunc runs a job once and reports how many items it processed.
+type JobFunc func() (int, error)
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+}
+
+// Scheduler runs every job on its own interval. Each job runs under its own
+// Postgres advisory lock, so with several replicas only one of them runs
+// a given job per tick. Each lock holds a connection of the pool while its
+// job runs.
+type Scheduler struct {
+	cfg    *config.Scheduler
+	locker repository.LockRepository
+	logger *zap.SugaredLogger
+	jobs   []*job
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	runs map[string]*models.JobRun
+}
+
+func New(cfg *config.Scheduler, locker repository.LockRepository, logger *zap.SugaredLogger) *Scheduler {
+	return &Scheduler{
+		cfg:    cfg,
+		locker: locker,
+		logger: logger,
+		runs:   make(map[string]*models.JobRun),
+	}
+}
+
+// Add registers a job running on the configured interval.
+func (s *Scheduler) Add(name string, run JobFunc) {
+	s.AddEvery(name, s.cfg.Interval, run)
+}
+
+// AddEvery registers a job running every interval unless it is disabled in
+// the config. Jobs must be added before Start.
+func (s *Scheduler) AddEvery(name string, interval time.Duration, run JobFunc) {
+	if enabled, ok := s.cfg.Jobs[name]; ok && !enabled {
+		s.logger.Infof("scheduler job %s is disabled", name)
+		return
+	}
+	s.jobs = append(s.jobs, &job{name: name, interval: interval, run: run})
+	s.runs[name] = &models.JobRun{Name: name, Interval: interval.String()}
+}
+
+// Start runs the jobs in the background until ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	if !s.cfg.Enabled || len(s.jobs) == 0 {
+		s.logger.Info("scheduler is disabled")
+		return
+	}
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go func(j *job) {
+			defer s.wg.Done()
+			ticker := time.NewTicker(j.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					s.runJob(j)
+				}
+			}
+		}(j)
+	}
+	s.logger.Infof("scheduler started with %d jobs", len(s.jobs))
+}
+
+// Wait blocks until the scheduler has stopped and the running jobs have
+// finished.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// Runs returns the statistics of every registered job.
+func (s *Scheduler) Runs() []*models.JobRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]*models.JobRun, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		run := *s.runs[j.name]
+		result = append(result, &run)
+	}
+	return result
+}
+
+func (s *Scheduler) runJob(j *job) {
+	started := time.Now()
+	var processed int
+	locked, err := s.locker.TryWithAdvisoryLock(context.Background(), "scheduler:"+j.name, func(context.Context) error {
+		var err error
+		processed, err = j.run()
+		return err
+	})
+	duration := time.Since(started)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run := s.runs[j.name]
+	if err == nil && !locked {
+		run.Skipped++
+		s.logger.Debugf("scheduler job %s is running on another replica, skipped", j.name)
+		return
+	}
+	run.Runs++
+	run.LastStartedAt = &started
+	run.LastDuration = duration.String()
+	run.LastProcessed = processed
+	run.TotalProcessed += processed
+	run.LastError = ""
+	if err != nil {
+		run.Failures++
+		run.LastError = err.Error()
+		s.logger.Errorf("scheduler job %s failed after %s: %v", j.name, duration, err)
+		return
+	}
+	if processed != 0 {
+		s.logger.Infof("scheduler job %s processed %d items in %s", j.name, processed, duration)
+	}
+}
@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/analyzer"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+)
+
+// analyze sends the answered questions to the analyzer, either in one
+// request or, with per-question analysis enabled, one request per question.
+// Only the latter reports the progress of each question as it is analyzed.
+func (s *interviewsService) analyze(ctx context.Context, publicID string, ticket analyzer.Ticket, questions []models.QuestionResult) (_ *models.Result, err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.analyze", publicID)
+	defer func() { endSpan(span, err) }()
+
+	if s.cfg.Analysis.PerQuestion {
+		return s.analyzeQuestions(ctx, publicID, ticket, questions)
+	}
+
+	req := &analyzer.Request{
+		Questions: make([]analyzer.Question, 0, len(questions)),
+	}
+	for _, q := range questions {
+		req.Questions = append(req.Questions, analyzerQuestion(q))
+	}
+	res, err := s.process(ctx, ticket, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := res.Result
+	s.metrics.ObserveQuestions(result.Questions)
+	if len(result.Questions) != 0 {
+		result.Score = result.Score / len(result.Questions)
+	}
+	return &result, nil
+}
+
+// analyzeQuestions analyzes the questions independently, at most
+// cfg.Analysis.Concurrency at a time. Questions that fail are listed in
+// FailedQuestions instead of failing the whole interview, unless all fail.
+func (s *interviewsService) analyzeQuestions(ctx context.Context, publicID string, ticket analyzer.Ticket, questions []models.QuestionResult) (*models.Result, error) {
+	concurrency := s.cfg.Analysis.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]*models.QuestionResult, len(questions))
+	errs := make([]error, len(questions))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+launch:
+	for i := range questions {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break launch
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			q, err := s.analyzeQuestion(ctx, publicID, ticket, questions[i])
+			if err != nil {
+				s.logger.Errorf("analysis of question %s of interview %s failed: %v", questions[i].PublicID, publicID, err)
+			}
+			results[i], errs[i] = q, err
+
+			mu.Lock()
+			done++
+			event := &models.ProgressEvent{
+				InterviewPublicID: publicID,
+				Type:              models.ProgressQuestion,
+				QuestionPublicID:  questions[i].PublicID,
+				Done:              done,
+				Total:             len(questions),
+			}
+			mu.Unlock()
+			s.progress(ctx, event)
+		}(i)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &models.Result{
+		Questions: make([]models.QuestionResult, 0, len(questions)),
+	}
+	for i, q := range results {
+		if q == nil {
+			result.FailedQuestions = append(result.FailedQuestions, questions[i].PublicID)
+			continue
+		}
+		result.Questions = append(result.Questions, *q)
+	}
+	if len(result.Questions) == 0 && len(questions) != 0 {
+		return nil, fmt.Errorf("all %d questions failed: %w", len(questions), errs[0])
+	}
+	result.Score = averageScore(result.Questions)
+	return result, nil
+}
+
+// analyzeQuestion analyzes a single question and stores the outcome, so
+// that completed questions survive a failure of the others. Calls rejected
+// by the limiter or cut short by ctx are not stored.
+func (s *interviewsService) analyzeQuestion(ctx context.Context, publicID string, ticket analyzer.Ticket, question models.QuestionResult) (_ *models.QuestionResult, err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.analyzeQuestion", publicID)
+	defer func() { endSpan(span, err) }()
+
+	release, err := s.acquire(ctx, ticket)
+	if err != nil {
+		return nil, err
+	}
+	var result *models.QuestionResult
+	res, err := s.analyzer.ProcessInterview(ctx, &analyzer.Request{
+		Questions: []analyzer.Question{analyzerQuestion(question)},
+	})
+	release()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err == nil {
+		for i := range res.Result.Questions {
+			if res.Result.Questions[i].PublicID == question.PublicID {
+				result = &res.Result.Questions[i]
+				break
+			}
+		}
+		if result == nil {
+			err = fmt.Errorf("analyzer returned no result for question %s", question.PublicID)
+		}
+	}
+
+	analysis := &models.QuestionAnalysis{
+		InterviewPublicID: publicID,
+		QuestionPublicID:  question.PublicID,
+		Status:            models.QuestionAnalyzed,
+		Result:            result,
+	}
+	if err != nil {
+		analysis.Status = models.QuestionAnalysisFailed
+		analysis.Error = truncate(err.Error(), maxFailureSize)
+		var analyzerErr *analyzer.Error
+		if errors.As(err, &analyzerErr) {
+			analysis.Response = truncate(analyzerErr.Body, maxFailureSize)
+		}
+	}
+	if saveErr := s.analysisRepo.SaveQuestionAnalysis(ctx, analysis); saveErr != nil {
+		s.logger.Errorf("could not save analysis of question %s of interview %s: %v", question.PublicID, publicID, saveErr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", models.ErrAnalysisFailed, err)
+	}
+	s.metrics.ObserveQuestions([]models.QuestionResult{*result})
+	return result, nil
+}
+
+// ReanalyzeQuestion runs the analysis of one question of an analyzed
+// interview again and recomputes the overall score. A manual score
+// override stays in place; its original score is updated instead. The
+// new result is merged into the one stored when it is saved, so that
+// changes made during the analysis are kept.
+func (s *interviewsService) ReanalyzeQuestion(ctx context.Context, publicID, questionPublicID string) (_ *models.InterviewResults, err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.ReanalyzeQuestion", publicID)
+	defer func() { endSpan(span, err) }()
+
+	interview, err := s.interviewRepo.GetInterview(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if interview.Status != models.InterviewStatusEvaluated && interview.Status != models.InterviewStatusFailed {
+		return nil, models.ErrResultNotReady
+	}
+	answered, err := s.interviewRepo.GetInterviewByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	var question *models.QuestionResult
+	for i := range answered.Result.Questions {
+		if answered.Result.Questions[i].PublicID == questionPublicID {
+			question = &answered.Result.Questions[i]
+			break
+		}
+	}
+	if question == nil {
+		return nil, models.ErrQuestionNotFound
+	}
+
+	ticket, err := s.ticket(ctx, publicID, true)
+	if err != nil {
+		return nil, err
+	}
+	analyzed, err := s.analyzeQuestion(ctx, publicID, ticket, *question)
+	if err != nil {
+		return nil, err
+	}
+	single := &models.Result{Questions: []models.QuestionResult{*analyzed}}
+	if err = s.enrich(ctx, publicID, single); err != nil {
+		return nil, err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		interview, err = s.interviewRepo.GetInterview(ctx, publicID)
+		if err != nil {
+			return err
+		}
+		if interview.Status != models.InterviewStatusEvaluated && interview.Status != models.InterviewStatusFailed {
+			return models.ErrResultNotReady
+		}
+
+		// keep the order in which the questions were answered
+		previous := make(map[string]models.QuestionResult, len(interview.Result.Questions))
+		for _, q := range interview.Result.Questions {
+			previous[q.PublicID] = q
+		}
+		previous[questionPublicID] = single.Questions[0]
+		questions := make([]models.QuestionResult, 0, len(previous))
+		failed := make([]string, 0)
+		for _, q := range answered.Result.Questions {
+			if r, ok := previous[q.PublicID]; ok {
+				questions = append(questions, r)
+			} else {
+				failed = append(failed, q.PublicID)
+			}
+		}
+		interview.Result.Questions = questions
+		interview.Result.FailedQuestions = failed
+		s.taxonomy.Normalize(&interview.Result)
+
+		score := averageScore(questions)
+		if interview.Result.Override != nil {
+			interview.Result.Override.OriginalScore = score
+		} else {
+			interview.Result.Score = score
+		}
+		return s.saveResult(ctx, interview, models.ResultSourceReanalysis)
+	})
+	if err != nil {
+		return nil, err
+	}
+	interview.Status = models.InterviewStatusEvaluated
+	s.index(ctx, publicID, &interview.Result)
+	s.flagFaces(ctx, publicID, single.Questions)
+	if err = s.decorate(ctx, interview); err != nil {
+		s.logger.Errorf("could not decorate result of interview %s: %v", publicID, err)
+	}
+	return interview, nil
+}
+
+func (s *interviewsService) GetQuestionAnalyses(ctx context.Context, publicID string) (_ []*models.QuestionAnalysis, err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.GetQuestionAnalyses", publicID)
+	defer func() { endSpan(span, err) }()
+
+	return s.analysisRepo.GetQuestionAnalyses(ctx, publicID)
+}
+
+func (s *interviewsService) GetAnalyzerQueue() *models.AnalyzerQueue {
+	return s.limiter.Stats()
+}
+
+// ticket prioritizes the analysis of the interview by the plan of its
+// company; re-analysis goes ahead of first analyses of the same plan.
+func (s *interviewsService) ticket(ctx context.Context, publicID string, reanalysis bool) (analyzer.Ticket, error) {
+	company, err := s.interviewRepo.GetInterviewCompany(ctx, publicID)
+	if err != nil {
+		return analyzer.Ticket{}, err
+	}
+	ticket := analyzer.Ticket{
+		Company:  company.CompanyPublicID,
+		Priority: s.cfg.Analysis.Limits.PlanPriorities[company.Plan],
+	}
+	if reanalysis {
+		ticket.Priority += s.cfg.Analysis.Limits.ReanalysisPriority
+	}
+	return ticket, nil
+}
+
+// process sends req to the analyzer once the limiter gives it a slot.
+func (s *interviewsService) process(ctx context.Context, ticket analyzer.Ticket, req *analyzer.Request) (*analyzer.Response, error) {
+	release, err := s.acquire(ctx, ticket)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return s.analyzer.ProcessInterview(ctx, req)
+}
+
+// enrich derives everything computed on top of the analyzer output:
+// canonical emotions, speech metrics and key point coverage.
+func (s *interviewsService) enrich(ctx context.Context, publicID string, result *models.Result) error {
+	if unknown := s.taxonomy.Normalize(result); len(unknown) != 0 {
+		s.logger.Warnf("interview %s has emotions missing from taxonomy: %v", publicID, unknown)
+	}
+	questionIDs := make([]string, 0, len(result.Questions))
+	for i := range result.Questions {
+		q := &result.Questions[i]
+		q.SpeechMetrics = s.speech.Analyze(q, answerLanguage(s.cfg.Search, q))
+		questionIDs = append(questionIDs, q.PublicID)
+	}
+	keyPoints, err := s.keyPointRepo.GetKeyPoints(ctx, questionIDs...)
+	if err != nil {
+		return err
+	}
+	applyCoverage(s.cfg.Coverage, s.cfg.Analysis.Contract, result, keyPoints)
+	return nil
+}
+
+// index makes the answers searchable and checks them for similarity;
+// failures are only logged.
+func (s *interviewsService) index(ctx context.Context, publicID string, result *models.Result) {
+	transcripts := transcriptsFromResult(s.cfg.Search, publicID, result)
+	if err := s.transcriptRepo.SaveTranscripts(ctx, publicID, transcripts); err != nil {
+		s.logger.Errorf("could not index transcripts of interview %s: %v", publicID, err)
+	} else if err = s.similarities.CheckInterview(ctx, publicID, transcripts); err != nil {
+		s.logger.Errorf("could not check answer similarity of interview %s: %v", publicID, err)
+	}
+}
+
+func analyzerQuestion(q models.QuestionResult) analyzer.Question {
+	return analyzer.Question{
+		PublicID:  q.PublicID,
+		Question:  q.Question,
+		VideoLink: q.VideoLink,
+	}
+}
+
+func averageScore(questions []models.QuestionResult) int {
+	if len(questions) == 0 {
+		return 0
+	}
+	total := 0
+	for _, q := range questions {
+		total += q.Score
+	}
+	return total / len(questions)
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// background runs the work that outlives the request starting it, such as
+// the analysis of a submitted interview, for as long as the app runs. The
+// work is cancelled with the app's context and waited for on shutdown.
+type background struct {
+	ctx context.Context
+
+	mu      sync.Mutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+func newBackground(ctx context.Context) *background {
+	return &background{ctx: ctx}
+}
+
+// Go runs fn in the background with the app's context, kept in the trace
+// of ctx. Once the background is stopped fn runs right away instead, with
+// its context already cancelled.
+func (b *background) Go(ctx context.Context, fn func(ctx context.Context)) {
+	ctx = trace.ContextWithSpanContext(b.ctx, trace.SpanContextFromContext(ctx))
+	b.mu.Lock()
+	if b.stopped {
+		b.mu.Unlock()
+		fn(ctx)
+		return
+	}
+	b.wg.Add(1)
+	b.mu.Unlock()
+	go func() {
+		defer b.wg.Done()
+		fn(ctx)
+	}()
+}
+
+// Wait stops the background from starting work and waits for the work
+// running. It is called once the app's context is cancelled.
+func (b *background) Wait() {
+	b.mu.Lock()
+	b.stopped = true
+	b.mu.Unlock()
+	b.wg.Wait()
+}
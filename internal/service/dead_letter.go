@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/analyzer"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+// maxFailureSize bounds the error and the response body kept for a failed
+// analysis.
+const maxFailureSize = 64 << 10
+
+type deadLettersService struct {
+	cfg            *config.Configs
+	logger         *zap.SugaredLogger
+	deadLetterRepo repository.DeadLetterRepository
+	interviews     *interviewsService
+	background     *background
+}
+
+func NewDeadLettersService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, interviews *interviewsService, bg *background) *deadLettersService {
+	return &deadLettersService{
+		deadLetterRepo: repo.DeadLetterRepository,
+		interviews:     interviews,
+		background:     bg,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+func (s *deadLettersService) GetFailedAnalyses(status string, args *models.SearchArgs) ([]*models.FailedAnalysis, error) {
+	switch status {
+	case "", models.FailedAnalysisFailed, models.FailedAnalysisRetrying, models.FailedAnalysisResolved, models.FailedAnalysisDiscarded:
+	default:
+		return nil, models.ErrInvalidInput
+	}
+	return s.deadLetterRepo.GetFailedAnalyses(status, args)
+}
+
+func (s *deadLettersService) GetFailedAnalysis(publicID string) (*models.FailedAnalysis, error) {
+	return s.deadLetterRepo.GetFailedAnalysis(publicID)
+}
+
+// RetryFailedAnalyses analyzes the interviews of the failed analyses among
+// publicIDs again in the background and returns the ones being retried.
+// Each retry resolves its failure or records another attempt.
+func (s *deadLettersService) RetryFailedAnalyses(publicIDs []string) ([]*models.FailedAnalysis, error) {
+	if len(publicIDs) == 0 {
+		return nil, models.ErrInvalidInput
+	}
+	failures, err := s.deadLetterRepo.ClaimFailedAnalyses(publicIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(failures) == 0 {
+		return failures, nil
+	}
+
+	s.background.Go(context.Background(), func(ctx context.Context) {
+		for _, f := range failures {
+			_, err := s.interviews.CreateInterviewResult(ctx, f.InterviewPublicID, "", true)
+			if err == nil {
+				continue
+			}
+			s.logger.Warnf("retry of failed analysis %s of interview %s failed: %v", f.PublicID, f.InterviewPublicID, err)
+			// failures the interview service did not record, such as
+			// rejected, concurrent or cancelled analyses, would otherwise
+			// stay retrying
+			if err = s.deadLetterRepo.ReleaseFailedAnalysis(detach(ctx), failedAnalysis(f.InterviewPublicID, err)); err != nil {
+				s.logger.Errorf("could not release failed analysis of interview %s: %v", f.InterviewPublicID, err)
+			}
+		}
+	})
+	return failures, nil
+}
+
+func (s *deadLettersService) DiscardFailedAnalyses(publicIDs []string) (int, error) {
+	if len(publicIDs) == 0 {
+		return 0, models.ErrInvalidInput
+	}
+	return s.deadLetterRepo.DiscardFailedAnalyses(publicIDs)
+}
+
+// recordFailure keeps the failure of the interview's analysis for the
+// admins to inspect and retry.
+func (s *interviewsService) recordFailure(ctx context.Context, publicID string, err error) {
+	if !recordable(err) {
+		return
+	}
+	if err = s.deadLetterRepo.RecordFailedAnalysis(ctx, failedAnalysis(publicID, err)); err != nil {
+		s.logger.Errorf("could not record failed analysis of interview %s: %v", publicID, err)
+	}
+}
+
+// recordable tells whether err is a failure to analyze the interview, as
+// opposed to a missing or unsubmitted interview or a call rejected for
+// backpressure, which the client retries itself.
+func recordable(err error) bool {
+	return !errors.Is(err, models.ErrInterviewNotFound) &&
+		!errors.Is(err, models.ErrNotSubmitted) &&
+		!errors.Is(err, models.ErrAnalyzerBusy) &&
+		!errors.Is(err, models.ErrAnalysisRateLimited)
+}
+
+func failedAnalysis(publicID string, err error) *models.FailedAnalysis {
+	failure := &models.FailedAnalysis{
+		InterviewPublicID: publicID,
+		Error:             truncate(err.Error(), maxFailureSize),
+	}
+	var analyzerErr *analyzer.Error
+	if errors.As(err, &analyzerErr) {
+		if analyzerErr.StatusCode != 0 {
+			code := analyzerErr.StatusCode
+			failure.StatusCode = &code
+		}
+		failure.ResponseBody = truncate(analyzerErr.Body, maxFailureSize)
+	}
+	return failure
+}
+
+func truncate(s string, size int) string {
+	if len(s) <= size {
+		return s
+	}
+	return strings.ToValidUTF8(s[:size], "")
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+type integrityService struct {
+	cfg           *config.Configs
+	logger        *zap.SugaredLogger
+	integrityRepo repository.IntegrityRepository
+}
+
+func NewIntegrityService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger) *integrityService {
+	return &integrityService{
+		integrityRepo: repo.IntegrityRepository,
+		cfg:           cfg,
+		logger:        logger,
+	}
+}
+
+// AddIntegrityEvents records the events the candidate's client reported
+// while taking the interview; the analyzer's events are never accepted
+// from it.
+func (s *integrityService) AddIntegrityEvents(interviewPublicID string, events []*models.IntegrityEvent) error {
+	for _, e := range events {
+		e.Source = models.IntegritySourceClient
+	}
+	return s.integrityRepo.AddIntegrityEvents(interviewPublicID, events)
+}
+
+// flagFaces records the moments the analyzer saw more than one face in the
+// answers to the questions as integrity events, replacing those of an
+// earlier analysis; failures are only logged. The events are dated from
+// the start of the question when the candidate took it through a session.
+func (s *interviewsService) flagFaces(ctx context.Context, publicID string, questions []models.QuestionResult) {
+	started := make(map[string]time.Time)
+	if session, err := s.interviewRepo.GetSessionQuestions(ctx, publicID); err == nil {
+		for _, q := range session {
+			if q.StartedAt != nil {
+				started[q.PublicID] = *q.StartedAt
+			}
+		}
+	}
+
+	questionIDs := make([]string, 0, len(questions))
+	events := make([]*models.IntegrityEvent, 0)
+	now := time.Now()
+	for _, q := range questions {
+		questionIDs = append(questionIDs, q.PublicID)
+		for _, at := range q.MultipleFaces {
+			occurredAt := now
+			if start, ok := started[q.PublicID]; ok {
+				occurredAt = start.Add(time.Duration(at * float64(time.Second)))
+			}
+			events = append(events, &models.IntegrityEvent{
+				Type:             models.IntegrityMultipleFaces,
+				QuestionPublicID: q.PublicID,
+				Source:           models.IntegritySourceAnalyzer,
+				OccurredAt:       occurredAt,
+				Details:          map[string]interface{}{"at": at},
+			})
+		}
+	}
+	if err := s.integrityRepo.ReplaceAnalyzerEvents(ctx, publicID, questionIDs, events); err != nil {
+		s.logger.Errorf("could not record faces seen in interview %s: %v", publicID, err)
+	}
+}
+
+// summarizeIntegrity weighs the event counts of an interview. A session is
+// suspicious once the weighted score reaches the configured threshold.
+func summarizeIntegrity(cfg *config.Integrity, counts []*models.IntegrityCount) *models.IntegritySummary {
+	summary := &models.IntegritySummary{
+		Events:    make(map[string]int),
+		Questions: make(map[string]map[string]int),
+	}
+	for _, c := range counts {
+		summary.Events[c.Type] += c.Count
+		summary.Total += c.Count
+		summary.Score += cfg.Weights[c.Type] * float64(c.Count)
+		if c.QuestionPublicID != "" {
+			if summary.Questions[c.QuestionPublicID] == nil {
+				summary.Questions[c.QuestionPublicID] = make(map[string]int)
+			}
+			summary.Questions[c.QuestionPublicID][c.Type] += c.Count
+		}
+	}
+	summary.Suspicious = summary.Total > 0 && summary.Score >= cfg.Threshold
+	return summary
+}
@@ -1,148 +1,420 @@
 package service
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net"
-	"net/http"
+	"errors"
 	"time"
 
 	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/analytics"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/analyzer"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/metrics"
 	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
 	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
 	"go.uber.org/zap"
 )
 
 type interviewsService struct {
-	cfg           *config.Configs
-	logger        *zap.SugaredLogger
-	interviewRepo repository.InterviewRepository
-}
-type QuestionReq struct {
-	Question  string `json:"question"`
-	PublicID  string `json:"public_id"`
-	VideoLink string `json:"video_link"`
+	cfg            *config.Configs
+	logger         *zap.SugaredLogger
+	interviewRepo  repository.InterviewRepository
+	transcriptRepo repository.TranscriptRepository
+	keyPointRepo   repository.KeyPointRepository
+	integrityRepo  repository.IntegrityRepository
+	progressRepo   repository.ProgressRepository
+	analysisRepo   repository.AnalysisRepository
+	deadLetterRepo repository.DeadLetterRepository
+	requestRepo    repository.IdempotencyRepository
+	lockRepo       repository.LockRepository
+	tx             repository.TxManager
+	analyzer       analyzer.Client
+	limiter        *analyzer.Limiter
+	taxonomy       *analytics.Taxonomy
+	speech         *analytics.SpeechAnalyzer
+	similarities   *similaritiesService
+	metrics        *metrics.Metrics
+	background     *background
 }
 
-type Request struct {
-	Questions []QuestionReq `json:"questions"`
+func NewInterviewsService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, similarities *similaritiesService, client analyzer.Client, limiter *analyzer.Limiter, m *metrics.Metrics, bg *background) *interviewsService {
+	return &interviewsService{
+		interviewRepo:  repo.InterviewRepository,
+		transcriptRepo: repo.TranscriptRepository,
+		keyPointRepo:   repo.KeyPointRepository,
+		integrityRepo:  repo.IntegrityRepository,
+		progressRepo:   repo.ProgressRepository,
+		analysisRepo:   repo.AnalysisRepository,
+		deadLetterRepo: repo.DeadLetterRepository,
+		requestRepo:    repo.IdempotencyRepository,
+		lockRepo:       repo.LockRepository,
+		tx:             repo.TxManager,
+		analyzer:       client,
+		limiter:        limiter,
+		taxonomy:       analytics.NewTaxonomy(cfg.Emotions),
+		speech:         analytics.NewSpeechAnalyzer(cfg.Speech),
+		similarities:   similarities,
+		metrics:        m,
+		background:     bg,
+		cfg:            cfg,
+		logger:         logger,
+	}
 }
-type Result struct {
-	Result models.Result `json:"result"`
+
+// AddVideoToQuestion attaches an answer uploaded outside of a session. The
+// interview was never started then, so it is submitted with its first video
+// to let its result be created.
+func (s *interviewsService) AddVideoToQuestion(ctx context.Context, questionPublicID, interviewPublicID, video string) (err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.AddVideoToQuestion", interviewPublicID)
+	defer func() { endSpan(span, err) }()
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.interviewRepo.AddVideoToQuestion(ctx, questionPublicID, interviewPublicID, video); err != nil {
+			return err
+		}
+		_, err := s.interviewRepo.TransitionInterview(ctx, interviewPublicID, []string{models.InterviewStatusPending}, models.InterviewStatusSubmitted)
+		return err
+	})
 }
 
-func NewInterviewsService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger) *interviewsService {
-	return &interviewsService{
-		interviewRepo: repo.InterviewRepository,
-		cfg:           cfg,
-		logger:        logger,
+// CreateInterviewResult analyzes the interview unless an analysis of it is
+// already running on any replica, in which case it fails with
+// models.ErrAnalysisRunning. A request repeated with the idempotency key of
+// a completed one, or made for an evaluated interview without asking to
+// reanalyze it, returns the stored result instead of analyzing again.
+func (s *interviewsService) CreateInterviewResult(ctx context.Context, publicID, idempotencyKey string, reanalyze bool) (_ *models.InterviewResults, err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.CreateInterviewResult", publicID)
+	defer func() { endSpan(span, err) }()
+
+	stored, err := s.storedResult(ctx, publicID, idempotencyKey, reanalyze)
+	if err != nil {
+		return nil, err
 	}
+	if stored {
+		return s.GetInterviewByPublicID(ctx, publicID)
+	}
+
+	var interview *models.InterviewResults
+	locked, err := s.lockRepo.TryWithAdvisoryLock(ctx, "analysis:"+publicID, func(ctx context.Context) error {
+		var err error
+		interview, err = s.createResultOnce(ctx, publicID, idempotencyKey, reanalyze)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, models.ErrAnalysisRunning
+	}
+	return interview, nil
 }
 
-func (s *interviewsService) AddVideoToQuestion(questionPublicID, interviewPublicID, video string) error {
-	return s.interviewRepo.AddVideoToQuestion(questionPublicID, interviewPublicID, video)
+// storedResult tells whether the request is answered with the stored
+// result: the request made with key, if any, was completed, or the
+// interview is evaluated and reanalyzing it was not asked for.
+func (s *interviewsService) storedResult(ctx context.Context, publicID, key string, reanalyze bool) (bool, error) {
+	if key != "" {
+		request, err := s.requestRepo.GetAnalysisRequest(ctx, publicID, key)
+		if err != nil {
+			return false, err
+		}
+		if request != nil && request.Status == models.AnalysisRequestCompleted {
+			return true, nil
+		}
+	}
+	if reanalyze {
+		return false, nil
+	}
+	session, err := s.interviewRepo.GetSession(ctx, publicID)
+	if err != nil {
+		return false, err
+	}
+	return session.Status == models.InterviewStatusEvaluated, nil
 }
 
-func (s *interviewsService) CreateInterviewResult(publicID string) (*models.InterviewResults, error) {
-	interview, err := s.interviewRepo.GetInterviewByPublicID(publicID)
+// createResultOnce analyzes the interview while holding its lock, unless
+// the stored result answers the request by the time the lock was taken. A
+// failure is recorded as a failed analysis and a success resolves the
+// recorded one; an analysis cut short by ctx is neither.
+func (s *interviewsService) createResultOnce(ctx context.Context, publicID, idempotencyKey string, reanalyze bool) (*models.InterviewResults, error) {
+	stored, err := s.storedResult(ctx, publicID, idempotencyKey, reanalyze)
 	if err != nil {
 		return nil, err
 	}
-	req := Request{
-		Questions: make([]QuestionReq, 0),
+	if stored {
+		return s.GetInterviewByPublicID(ctx, publicID)
 	}
+	s.setRequest(ctx, publicID, idempotencyKey, models.AnalysisRequestRunning)
+	interview, err := s.createResult(ctx, publicID)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.recordFailure(ctx, publicID, err)
+		}
+		s.setRequest(detach(ctx), publicID, idempotencyKey, models.AnalysisRequestFailed)
+		return nil, err
+	}
+	if err = s.deadLetterRepo.ResolveFailedAnalysis(ctx, publicID); err != nil {
+		s.logger.Errorf("could not resolve failed analysis of interview %s: %v", publicID, err)
+	}
+	s.setRequest(detach(ctx), publicID, idempotencyKey, models.AnalysisRequestCompleted)
+	return interview, nil
+}
 
-	for _, q := range interview.Result.Questions {
-		req.Questions = append(req.Questions, QuestionReq{
-			PublicID:  q.PublicID,
-			Question:  q.Question,
-			VideoLink: q.VideoLink,
-		})
+// setRequest records the status of the request made with key, if any;
+// failures are only logged.
+func (s *interviewsService) setRequest(ctx context.Context, publicID, key, status string) {
+	if key == "" {
+		return
+	}
+	if err := s.requestRepo.SetAnalysisRequest(ctx, publicID, key, status); err != nil {
+		s.logger.Errorf("could not record analysis request %s of interview %s: %v", key, publicID, err)
 	}
-	res, err := sendDataToAPI(req, s.cfg.Video.Url)
+}
+
+func (s *interviewsService) createResult(ctx context.Context, publicID string) (_ *models.InterviewResults, err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.createResult", publicID)
+	defer func() { endSpan(span, err) }()
+
+	session, err := s.interviewRepo.GetSession(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasStatus(session.Status, resultStatuses) {
+		return nil, models.ErrNotSubmitted
+	}
+	interview, err := s.interviewRepo.GetInterviewByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	ticket, err := s.ticket(ctx, publicID, false)
 	if err != nil {
-		s.logger.Error(err)
 		return nil, err
 	}
-	if res != nil {
-		interview.Result = res.Result
-		if len(res.Result.Questions) != 0 {
-			interview.Result.Score = res.Result.Score / len(res.Result.Questions)
+	s.progress(ctx, &models.ProgressEvent{
+		InterviewPublicID: publicID,
+		Type:              models.ProgressAnalysis,
+		Stage:             models.AnalysisStarted,
+		Total:             len(interview.Result.Questions),
+	})
+	result, err := s.analyze(ctx, publicID, ticket, interview.Result.Questions)
+	if err != nil {
+		s.logger.Error(err)
+		// the client retries later on backpressure or after giving up on
+		// the request, so the interview stays unanalyzed
+		if errors.Is(err, models.ErrAnalyzerBusy) || errors.Is(err, models.ErrAnalysisRateLimited) || ctx.Err() != nil {
+			return nil, err
+		}
+		if _, statusErr := s.interviewRepo.TransitionInterview(ctx, publicID, unanalyzedStatuses, models.InterviewStatusFailed); statusErr != nil {
+			s.logger.Errorf("could not mark interview %s as failed: %v", publicID, statusErr)
 		}
+		return nil, err
 	}
+	interview.Result = *result
+	s.progress(ctx, &models.ProgressEvent{
+		InterviewPublicID: publicID,
+		Type:              models.ProgressAnalysis,
+		Stage:             models.AnalysisAnalyzed,
+		Total:             len(interview.Result.Questions),
+	})
 
-	interview.RawResult, err = json.Marshal(res.Result)
+	interview.RawResult, err = json.Marshal(interview.Result)
 	if err != nil {
 		s.logger.Error(err)
 		return nil, err
 	}
+	if err = s.enrich(ctx, publicID, &interview.Result); err != nil {
+		return nil, err
+	}
 	interview.PublicID = publicID
 
-	err = s.interviewRepo.PutInterview(interview)
+	// a manual score outlives the analysis it overrode
+	analyzed := interview.Result.Score
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		stored, err := s.interviewRepo.GetInterview(ctx, publicID)
+		if err != nil {
+			return err
+		}
+		interview.Result.Score = analyzed
+		interview.Result.Override = nil
+		if stored.Result.Override != nil {
+			override := *stored.Result.Override
+			override.OriginalScore = analyzed
+			interview.Result.Score = stored.Result.Score
+			interview.Result.Override = &override
+		}
+		return s.saveResult(ctx, interview, models.ResultSourceAnalysis)
+	})
 	if err != nil {
 		return nil, err
 	}
+	interview.Status = models.InterviewStatusEvaluated
+	s.index(ctx, publicID, &interview.Result)
+	s.flagFaces(ctx, publicID, interview.Result.Questions)
+	if err = s.decorate(ctx, interview); err != nil {
+		// the result is saved, so it is returned without the derived data
+		s.logger.Errorf("could not decorate result of interview %s: %v", publicID, err)
+	}
 
 	return interview, nil
 }
 
-func (s *interviewsService) GetInterviewByPublicID(publicID string) (*models.InterviewResults, error) {
-	return s.interviewRepo.GetInterview(publicID)
-}
+func (s *interviewsService) GetInterviewByPublicID(ctx context.Context, publicID string) (_ *models.InterviewResults, err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.GetInterviewByPublicID", publicID)
+	defer func() { endSpan(span, err) }()
 
-func (s *interviewsService) GetAllInterviews() ([]*models.InterviewResults, error) {
-	return s.interviewRepo.GetAllInterviews()
+	interview, err := s.interviewRepo.GetInterview(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.decorate(ctx, interview); err != nil {
+		return nil, err
+	}
+	return interview, nil
 }
 
-func dialTimeout(network, addr string) (net.Conn, error) {
-	return net.DialTimeout(network, addr, 600*time.Second)
-}
+func (s *interviewsService) GetAllInterviews(ctx context.Context) (_ []*models.InterviewResults, err error) {
+	ctx, span := tracer.Start(ctx, "InterviewsService.GetAllInterviews")
+	defer func() { endSpan(span, err) }()
 
-func sendDataToAPI(data Request, url string) (*Result, error) {
-	transport := http.Transport{
-		Dial: dialTimeout,
+	interviews, err := s.interviewRepo.GetAllInterviews(ctx)
+	if err != nil {
+		return nil, err
 	}
-	// Convert the InterviewResults struct to JSON
-	client := &http.Client{
-		Transport: &transport, // Set the timeout duration here
+	if err = s.decorate(ctx, interviews...); err != nil {
+		return nil, err
 	}
-	jsonData, err := json.Marshal(data)
+	return interviews, nil
+}
+
+// GetProgress returns the current state of the interview as the first
+// event of a progress stream.
+func (s *interviewsService) GetProgress(ctx context.Context, publicID string) (_ *models.ProgressEvent, err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.GetProgress", publicID)
+	defer func() { endSpan(span, err) }()
+
+	session, err := s.interviewRepo.GetSession(ctx, publicID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal data to JSON: %v", err)
+		return nil, err
 	}
-	// Create a request body with the JSON data
-	body := bytes.NewReader(jsonData)
+	return &models.ProgressEvent{
+		InterviewPublicID: session.PublicID,
+		Type:              models.ProgressStatus,
+		Status:            session.Status,
+		Done:              session.QuestionsAnswered,
+		Total:             session.QuestionsTotal,
+		At:                time.Now(),
+	}, nil
+}
 
-	// Send a POST request to the API endpoint
-	resp, err := client.Post(url+"/process_interview", "application/json", body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request to API: %v", err)
+// OverrideScore replaces the overall score of an evaluated interview.
+func (s *interviewsService) OverrideScore(ctx context.Context, publicID string, score int, reason string) (_ *models.InterviewResults, err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.OverrideScore", publicID)
+	defer func() { endSpan(span, err) }()
+
+	if score < s.cfg.Analysis.Contract.MinScore || score > s.cfg.Analysis.Contract.MaxScore {
+		return nil, models.ErrInvalidInput
 	}
-	defer resp.Body.Close()
-	statusCode := resp.StatusCode
-	// Check the response status code
-	if statusCode != http.StatusOK {
-		if statusCode == http.StatusUnprocessableEntity {
-			respBody, err := ioutil.ReadAll(resp.Body)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read response body: %v", respBody)
-			}
-			return nil, fmt.Errorf("API request failed with status code %d. %v", resp.StatusCode, string(respBody))
+	var interview *models.InterviewResults
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		interview, err = s.interviewRepo.GetInterview(ctx, publicID)
+		if err != nil {
+			return err
+		}
+		if interview.Status != models.InterviewStatusEvaluated {
+			return models.ErrResultNotReady
+		}
+
+		override := &models.ScoreOverride{
+			OriginalScore: interview.Result.Score,
+			Reason:        reason,
+			OverriddenAt:  time.Now(),
+		}
+		if interview.Result.Override != nil {
+			override.OriginalScore = interview.Result.Override.OriginalScore
 		}
-		return nil, fmt.Errorf("API request failed with status code %d", resp.StatusCode)
+		interview.Result.Score = score
+		interview.Result.Override = override
+		if err = s.interviewRepo.PutInterview(ctx, interview); err != nil {
+			return err
+		}
+		_, err = s.interviewRepo.AddResultVersion(ctx, publicID, models.ResultSourceOverride, &interview.Result)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.ObserveResult(models.ResultSourceOverride, &interview.Result)
+
+	if err = s.decorate(ctx, interview); err != nil {
+		s.logger.Errorf("could not decorate result of interview %s: %v", publicID, err)
+	}
+	return interview, nil
+}
+
+func (s *interviewsService) GetResultVersions(ctx context.Context, publicID string) (_ []*models.ResultVersion, err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.GetResultVersions", publicID)
+	defer func() { endSpan(span, err) }()
+
+	if _, err := s.interviewRepo.GetSession(ctx, publicID); err != nil {
+		return nil, err
 	}
-	respBody, err := ioutil.ReadAll(resp.Body)
+	return s.interviewRepo.GetResultVersions(ctx, publicID)
+}
+
+// saveResult stores the result of the interview along with a new version
+// of it and marks the interview evaluated, all or nothing. It fails with
+// models.ErrNotSubmitted when the interview was reopened meanwhile.
+func (s *interviewsService) saveResult(ctx context.Context, interview *models.InterviewResults, source string) (err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.saveResult", interview.PublicID)
+	defer func() { endSpan(span, err) }()
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.interviewRepo.PutInterview(ctx, interview); err != nil {
+			return err
+		}
+		if _, err := s.interviewRepo.AddResultVersion(ctx, interview.PublicID, source, &interview.Result); err != nil {
+			return err
+		}
+		evaluated, err := s.interviewRepo.TransitionInterview(ctx, interview.PublicID, resultStatuses, models.InterviewStatusEvaluated)
+		if err != nil {
+			return err
+		}
+		if !evaluated {
+			return models.ErrNotSubmitted
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+		return err
 	}
-	fmt.Println(string(respBody))
-	var responseData Result
-	err = json.Unmarshal(respBody, &responseData)
+	s.metrics.ObserveResult(source, &interview.Result)
+	return nil
+}
+
+// decorate attaches the data derived on read: emotion analytics and the
+// integrity summary.
+func (s *interviewsService) decorate(ctx context.Context, interviews ...*models.InterviewResults) error {
+	publicIDs := make([]string, 0, len(interviews))
+	for _, interview := range interviews {
+		publicIDs = append(publicIDs, interview.PublicID)
+	}
+	counts, err := s.integrityRepo.GetIntegrityCounts(ctx, publicIDs...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response body: %v", err)
+		return err
 	}
+	for _, interview := range interviews {
+		analytics.Interview(&interview.Result, s.cfg.Analytics.HeatmapWindow)
+		interview.Integrity = summarizeIntegrity(s.cfg.Integrity, counts[interview.PublicID])
+	}
+	return nil
+}
 
-	return &responseData, nil
+// progress publishes event to the clients following the interview;
+// failures are only logged.
+func (s *interviewsService) progress(ctx context.Context, event *models.ProgressEvent) {
+	event.At = time.Now()
+	if err := s.progressRepo.NotifyProgress(ctx, event); err != nil {
+		s.logger.Warnf("could not publish progress of interview %s: %v", event.InterviewPublicID, err)
+	}
 }
@@ -0,0 +1,532 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/analyzer"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
+	"github.com/creasty/defaults"
+	"go.uber.org/zap"
+)
+
+// fixtures recorded from the analyzer for the questions below
+const fixturesDir = "../../testdata/analyzer"
+
+var (
+	projectQuestion = repository.MemoryQuestion{
+		PublicID: "6f1c2a9e-3b1d-4c8e-9a57-1d2e3f4a5b01",
+		Question: "Tell us about a project you are proud of.",
+	}
+	conflictQuestion = repository.MemoryQuestion{
+		PublicID: "6f1c2a9e-3b1d-4c8e-9a57-1d2e3f4a5b02",
+		Question: "How do you handle disagreements in a team?",
+	}
+	motivationQuestion = repository.MemoryQuestion{
+		PublicID: "6f1c2a9e-3b1d-4c8e-9a57-1d2e3f4a5b03",
+		Question: "Why do you want to join us?",
+	}
+)
+
+// fakeRepos stands in for the repositories around the interviews and
+// keeps what the service writes to them. Methods the analysis doesn't
+// use are left to the embedded nil interfaces.
+type fakeRepos struct {
+	repository.TranscriptRepository
+	repository.KeyPointRepository
+	repository.SimilarityRepository
+	repository.IntegrityRepository
+	repository.ProgressRepository
+	repository.AnalysisRepository
+	repository.DeadLetterRepository
+	repository.IdempotencyRepository
+	repository.LockRepository
+
+	mu          sync.Mutex
+	transcripts map[string][]*models.Transcript
+	faces       map[string][]*models.IntegrityEvent
+	progress    []*models.ProgressEvent
+	analyses    []*models.QuestionAnalysis
+	failures    map[string]*models.FailedAnalysis
+	requests    map[string]string
+	locks       map[string]bool
+}
+
+func newFakeRepos() *fakeRepos {
+	return &fakeRepos{
+		transcripts: make(map[string][]*models.Transcript),
+		faces:       make(map[string][]*models.IntegrityEvent),
+		failures:    make(map[string]*models.FailedAnalysis),
+		requests:    make(map[string]string),
+		locks:       make(map[string]bool),
+	}
+}
+
+func (r *fakeRepos) SaveTranscripts(ctx context.Context, interviewPublicID string, transcripts []*models.Transcript) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transcripts[interviewPublicID] = transcripts
+	return nil
+}
+
+func (r *fakeRepos) GetPositionTranscripts(ctx context.Context, interviewPublicID string, questionPublicIDs []string) ([]*models.Transcript, error) {
+	return nil, nil
+}
+
+func (r *fakeRepos) ReplaceSimilarities(ctx context.Context, interviewPublicID string, similarities []*models.AnswerSimilarity) error {
+	return nil
+}
+
+func (r *fakeRepos) GetKeyPoints(ctx context.Context, questionPublicIDs ...string) (map[string][]*models.KeyPoint, error) {
+	return make(map[string][]*models.KeyPoint), nil
+}
+
+func (r *fakeRepos) ReplaceAnalyzerEvents(ctx context.Context, interviewPublicID string, questionPublicIDs []string, events []*models.IntegrityEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.faces[interviewPublicID] = events
+	return nil
+}
+
+func (r *fakeRepos) GetIntegrityCounts(ctx context.Context, interviewPublicIDs ...string) (map[string][]*models.IntegrityCount, error) {
+	return make(map[string][]*models.IntegrityCount), nil
+}
+
+func (r *fakeRepos) NotifyProgress(ctx context.Context, event *models.ProgressEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress = append(r.progress, event)
+	return nil
+}
+
+func (r *fakeRepos) SaveQuestionAnalysis(ctx context.Context, analysis *models.QuestionAnalysis) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.analyses = append(r.analyses, analysis)
+	return nil
+}
+
+func (r *fakeRepos) RecordFailedAnalysis(ctx context.Context, failure *models.FailedAnalysis) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	failure.Status = models.FailedAnalysisFailed
+	r.failures[failure.InterviewPublicID] = failure
+	return nil
+}
+
+func (r *fakeRepos) ResolveFailedAnalysis(ctx context.Context, interviewPublicID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if failure, ok := r.failures[interviewPublicID]; ok {
+		failure.Status = models.FailedAnalysisResolved
+	}
+	return nil
+}
+
+func (r *fakeRepos) GetAnalysisRequest(ctx context.Context, interviewPublicID, key string) (*models.AnalysisRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status, ok := r.requests[interviewPublicID+"/"+key]
+	if !ok {
+		return nil, nil
+	}
+	return &models.AnalysisRequest{InterviewPublicID: interviewPublicID, IdempotencyKey: key, Status: status}, nil
+}
+
+func (r *fakeRepos) SetAnalysisRequest(ctx context.Context, interviewPublicID, key, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests[interviewPublicID+"/"+key] = status
+	return nil
+}
+
+func (r *fakeRepos) TryWithAdvisoryLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	r.mu.Lock()
+	if r.locks[name] {
+		r.mu.Unlock()
+		return false, nil
+	}
+	r.locks[name] = true
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.locks, name)
+		r.mu.Unlock()
+	}()
+	return true, fn(ctx)
+}
+
+func (r *fakeRepos) stages(publicID string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stages := make([]string, 0)
+	for _, event := range r.progress {
+		if event.InterviewPublicID == publicID && event.Type == models.ProgressAnalysis {
+			stages = append(stages, event.Stage)
+		}
+	}
+	return stages
+}
+
+func testConfig(t *testing.T) *config.Configs {
+	t.Helper()
+	cfg := &config.Configs{
+		Token:      &config.Token{TokenSecret: "secret"},
+		Analytics:  &config.Analytics{},
+		Emotions:   &config.Emotions{},
+		Search:     &config.Search{},
+		Speech:     &config.Speech{},
+		Coverage:   &config.Coverage{},
+		Similarity: &config.Similarity{},
+		Integrity:  &config.Integrity{},
+		Interview:  &config.Interview{},
+		Analysis: &config.Analysis{
+			Limits:   &config.AnalyzerLimits{},
+			Contract: &config.AnalyzerContract{},
+			Fixtures: &config.AnalyzerFixtures{},
+		},
+	}
+	if err := defaults.Set(cfg); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// newTestInterviewsService returns the service analyzing the interviews of
+// repo with the recorded analyzer fixtures.
+func newTestInterviewsService(t *testing.T, cfg *config.Configs, repo *repository.MemoryInterviewRepository, fakes *fakeRepos) *interviewsService {
+	t.Helper()
+	client, err := analyzer.NewReplayer(fixturesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client, err = analyzer.NewValidating(client, cfg.Analysis.Contract); err != nil {
+		t.Fatal(err)
+	}
+	repos := &repository.Repository{
+		InterviewRepository:   repo,
+		TranscriptRepository:  fakes,
+		KeyPointRepository:    fakes,
+		SimilarityRepository:  fakes,
+		IntegrityRepository:   fakes,
+		ProgressRepository:    fakes,
+		AnalysisRepository:    fakes,
+		DeadLetterRepository:  fakes,
+		IdempotencyRepository: fakes,
+		LockRepository:        fakes,
+		TxManager:             repository.MemoryTxManager{},
+	}
+	logger := zap.NewNop().Sugar()
+	return NewInterviewsService(repos, cfg, logger, NewSimilaritiesService(repos, cfg, logger), client,
+		analyzer.NewLimiter(cfg.Analysis.Limits), nil, newBackground(context.Background()))
+}
+
+// addAnswered seeds a submitted interview with every question answered.
+func addAnswered(t *testing.T, repo *repository.MemoryInterviewRepository, publicID string, questions ...repository.MemoryQuestion) {
+	t.Helper()
+	repo.AddInterview(repository.MemoryInterview{
+		PublicID:          publicID,
+		CandidatePublicID: "c0a80121-7ac0-4e1c-9f1b-2f5c1d8e3a10",
+		CompanyPublicID:   "b3c1d2e4-5f60-4a7b-8c9d-0e1f2a3b4c5d",
+		Status:            models.InterviewStatusSubmitted,
+		Questions:         questions,
+	})
+	for _, q := range questions {
+		if err := repo.AddVideoToQuestion(context.Background(), q.PublicID, publicID, "videos/"+q.PublicID[len(q.PublicID)-2:]+".mp4"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// statuses returns the statuses the interview went through, in order.
+func statuses(t *testing.T, repo *repository.MemoryInterviewRepository, publicID string) []string {
+	t.Helper()
+	result := make([]string, 0)
+	for _, event := range repo.OutboxEvents() {
+		var payload struct {
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			t.Fatal(err)
+		}
+		if event.AggregateID == publicID && event.Event == models.OutboxInterviewEventPrefix+payload.Status {
+			result = append(result, payload.Status)
+		}
+	}
+	return result
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCreateInterviewResult(t *testing.T) {
+	const publicID = "0d5e8f1a-2b3c-4d5e-8f6a-7b8c9d0e1f21"
+	repo := repository.NewMemoryInterviewRepository()
+	addAnswered(t, repo, publicID, projectQuestion, conflictQuestion)
+	fakes := newFakeRepos()
+	s := newTestInterviewsService(t, testConfig(t), repo, fakes)
+
+	interview, err := s.CreateInterviewResult(context.Background(), publicID, "", false)
+	if err != nil {
+		t.Fatalf("CreateInterviewResult() error = %v", err)
+	}
+	if interview.Status != models.InterviewStatusEvaluated {
+		t.Errorf("status = %q, want %q", interview.Status, models.InterviewStatusEvaluated)
+	}
+	// the analyzer scores the questions 8 and 6
+	if interview.Result.Score != 7 {
+		t.Errorf("score = %d, want 7", interview.Result.Score)
+	}
+
+	saved, err := repo.GetInterview(context.Background(), publicID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Status != models.InterviewStatusEvaluated || saved.Result.Score != 7 || len(saved.Result.Questions) != 2 {
+		t.Errorf("saved interview = %s with score %d and %d questions, want evaluated with score 7 and 2 questions",
+			saved.Status, saved.Result.Score, len(saved.Result.Questions))
+	}
+	for _, q := range saved.Result.Questions {
+		if q.Answer == "" || q.SpeechMetrics == nil {
+			t.Errorf("saved question %s misses its answer or speech metrics", q.PublicID)
+		}
+	}
+	versions, err := repo.GetResultVersions(context.Background(), publicID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || versions[0].Source != models.ResultSourceAnalysis || versions[0].Score != 7 {
+		t.Errorf("versions = %+v, want one analysis version with score 7", versions)
+	}
+
+	if got := statuses(t, repo, publicID); !equalStrings(got, []string{models.InterviewStatusEvaluated}) {
+		t.Errorf("status transitions = %v, want [evaluated]", got)
+	}
+	if got := fakes.stages(publicID); !equalStrings(got, []string{models.AnalysisStarted, models.AnalysisAnalyzed}) {
+		t.Errorf("progress stages = %v, want [started analyzed]", got)
+	}
+	if n := len(fakes.transcripts[publicID]); n != 2 {
+		t.Errorf("indexed %d transcripts, want 2", n)
+	}
+	if n := len(fakes.faces[publicID]); n != 1 {
+		t.Errorf("flagged %d multiple faces events, want 1", n)
+	}
+	if len(fakes.failures) != 0 {
+		t.Errorf("recorded failures %v, want none", fakes.failures)
+	}
+}
+
+func TestCreateInterviewResultPerQuestion(t *testing.T) {
+	const publicID = "0d5e8f1a-2b3c-4d5e-8f6a-7b8c9d0e1f22"
+	repo := repository.NewMemoryInterviewRepository()
+	addAnswered(t, repo, publicID, projectQuestion, conflictQuestion)
+	fakes := newFakeRepos()
+	cfg := testConfig(t)
+	cfg.Analysis.PerQuestion = true
+	s := newTestInterviewsService(t, cfg, repo, fakes)
+
+	interview, err := s.CreateInterviewResult(context.Background(), publicID, "", false)
+	if err != nil {
+		t.Fatalf("CreateInterviewResult() error = %v", err)
+	}
+	// the video of the second question could not be decoded
+	if interview.Result.Score != 8 {
+		t.Errorf("score = %d, want 8", interview.Result.Score)
+	}
+	if len(interview.Result.Questions) != 1 || !equalStrings(interview.Result.FailedQuestions, []string{conflictQuestion.PublicID}) {
+		t.Errorf("result has %d questions and failed %v, want 1 and [%s]",
+			len(interview.Result.Questions), interview.Result.FailedQuestions, conflictQuestion.PublicID)
+	}
+	if got := statuses(t, repo, publicID); !equalStrings(got, []string{models.InterviewStatusEvaluated}) {
+		t.Errorf("status transitions = %v, want [evaluated]", got)
+	}
+
+	analyses := make(map[string]string)
+	for _, a := range fakes.analyses {
+		analyses[a.QuestionPublicID] = a.Status
+	}
+	if analyses[projectQuestion.PublicID] != models.QuestionAnalyzed || analyses[conflictQuestion.PublicID] != models.QuestionAnalysisFailed {
+		t.Errorf("question analyses = %v, want the first analyzed and the second failed", analyses)
+	}
+}
+
+func TestCreateInterviewResultFailure(t *testing.T) {
+	const publicID = "0d5e8f1a-2b3c-4d5e-8f6a-7b8c9d0e1f23"
+	repo := repository.NewMemoryInterviewRepository()
+	addAnswered(t, repo, publicID, motivationQuestion)
+	fakes := newFakeRepos()
+	s := newTestInterviewsService(t, testConfig(t), repo, fakes)
+
+	_, err := s.CreateInterviewResult(context.Background(), publicID, "key-1", false)
+	var analyzerErr *analyzer.Error
+	if !errors.As(err, &analyzerErr) || analyzerErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("CreateInterviewResult() error = %v, want the analyzer's 500", err)
+	}
+
+	session, err := repo.GetSession(context.Background(), publicID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Status != models.InterviewStatusFailed {
+		t.Errorf("status = %q, want %q", session.Status, models.InterviewStatusFailed)
+	}
+	if got := statuses(t, repo, publicID); !equalStrings(got, []string{models.InterviewStatusFailed}) {
+		t.Errorf("status transitions = %v, want [failed]", got)
+	}
+	failure := fakes.failures[publicID]
+	if failure == nil || failure.StatusCode == nil || *failure.StatusCode != http.StatusInternalServerError {
+		t.Errorf("recorded failure = %+v, want one with status code 500", failure)
+	}
+	if status := fakes.requests[publicID+"/key-1"]; status != models.AnalysisRequestFailed {
+		t.Errorf("request status = %q, want %q", status, models.AnalysisRequestFailed)
+	}
+}
+
+func TestCreateInterviewResultIdempotent(t *testing.T) {
+	const publicID = "0d5e8f1a-2b3c-4d5e-8f6a-7b8c9d0e1f24"
+	repo := repository.NewMemoryInterviewRepository()
+	addAnswered(t, repo, publicID, projectQuestion, conflictQuestion)
+	fakes := newFakeRepos()
+	s := newTestInterviewsService(t, testConfig(t), repo, fakes)
+
+	for i := 0; i < 2; i++ {
+		interview, err := s.CreateInterviewResult(context.Background(), publicID, "key-1", false)
+		if err != nil {
+			t.Fatalf("CreateInterviewResult() #%d error = %v", i+1, err)
+		}
+		if interview.Result.Score != 7 {
+			t.Errorf("score #%d = %d, want 7", i+1, interview.Result.Score)
+		}
+	}
+	versions, err := repo.GetResultVersions(context.Background(), publicID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 {
+		t.Errorf("got %d result versions, want the repeated request not to analyze again", len(versions))
+	}
+	if status := fakes.requests[publicID+"/key-1"]; status != models.AnalysisRequestCompleted {
+		t.Errorf("request status = %q, want %q", status, models.AnalysisRequestCompleted)
+	}
+}
+
+func TestCreateInterviewResultEvaluated(t *testing.T) {
+	const publicID = "0d5e8f1a-2b3c-4d5e-8f6a-7b8c9d0e1f28"
+	repo := repository.NewMemoryInterviewRepository()
+	addAnswered(t, repo, publicID, projectQuestion, conflictQuestion)
+	fakes := newFakeRepos()
+	s := newTestInterviewsService(t, testConfig(t), repo, fakes)
+
+	for i, reanalyze := range []bool{false, false, true} {
+		interview, err := s.CreateInterviewResult(context.Background(), publicID, "", reanalyze)
+		if err != nil {
+			t.Fatalf("CreateInterviewResult() #%d error = %v", i+1, err)
+		}
+		if interview.Status != models.InterviewStatusEvaluated || interview.Result.Score != 7 {
+			t.Errorf("interview #%d = %s with score %d, want evaluated with score 7", i+1, interview.Status, interview.Result.Score)
+		}
+	}
+	versions, err := repo.GetResultVersions(context.Background(), publicID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the repeated request returns the stored result, reanalyzing does not
+	if len(versions) != 2 || versions[1].Source != models.ResultSourceAnalysis {
+		t.Errorf("versions = %+v, want two analysis versions", versions)
+	}
+}
+
+func TestCreateInterviewResultKeepsOverride(t *testing.T) {
+	const publicID = "0d5e8f1a-2b3c-4d5e-8f6a-7b8c9d0e1f27"
+	repo := repository.NewMemoryInterviewRepository()
+	addAnswered(t, repo, publicID, projectQuestion, conflictQuestion)
+	fakes := newFakeRepos()
+	s := newTestInterviewsService(t, testConfig(t), repo, fakes)
+
+	if _, err := s.CreateInterviewResult(context.Background(), publicID, "", false); err != nil {
+		t.Fatalf("CreateInterviewResult() error = %v", err)
+	}
+	if _, err := s.OverrideScore(context.Background(), publicID, 9, "strong portfolio"); err != nil {
+		t.Fatalf("OverrideScore() error = %v", err)
+	}
+	if _, err := s.CreateInterviewResult(context.Background(), publicID, "", true); err != nil {
+		t.Fatalf("CreateInterviewResult() again error = %v", err)
+	}
+
+	saved, err := repo.GetInterview(context.Background(), publicID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	override := saved.Result.Override
+	if saved.Result.Score != 9 || override == nil || override.OriginalScore != 7 || override.Reason != "strong portfolio" {
+		t.Errorf("saved score = %d with override %+v, want 9 overriding 7", saved.Result.Score, override)
+	}
+}
+
+func TestCreateInterviewResultNotSubmitted(t *testing.T) {
+	const publicID = "0d5e8f1a-2b3c-4d5e-8f6a-7b8c9d0e1f25"
+	repo := repository.NewMemoryInterviewRepository()
+	repo.AddInterview(repository.MemoryInterview{
+		PublicID:          publicID,
+		CandidatePublicID: "c0a80121-7ac0-4e1c-9f1b-2f5c1d8e3a10",
+		Status:            models.InterviewStatusInProgress,
+		Questions:         []repository.MemoryQuestion{projectQuestion},
+	})
+	fakes := newFakeRepos()
+	s := newTestInterviewsService(t, testConfig(t), repo, fakes)
+
+	if _, err := s.CreateInterviewResult(context.Background(), publicID, "", false); !errors.Is(err, models.ErrNotSubmitted) {
+		t.Fatalf("CreateInterviewResult() error = %v, want %v", err, models.ErrNotSubmitted)
+	}
+	if got := statuses(t, repo, publicID); len(got) != 0 {
+		t.Errorf("status transitions = %v, want none", got)
+	}
+	if len(fakes.failures) != 0 {
+		t.Errorf("recorded failures %v, want none", fakes.failures)
+	}
+}
+
+func TestCreateInterviewResultLegacyVideos(t *testing.T) {
+	const publicID = "0d5e8f1a-2b3c-4d5e-8f6a-7b8c9d0e1f26"
+	repo := repository.NewMemoryInterviewRepository()
+	repo.AddInterview(repository.MemoryInterview{
+		PublicID:          publicID,
+		CandidatePublicID: "c0a80121-7ac0-4e1c-9f1b-2f5c1d8e3a10",
+		CompanyPublicID:   "b3c1d2e4-5f60-4a7b-8c9d-0e1f2a3b4c5d",
+		Questions:         []repository.MemoryQuestion{projectQuestion, conflictQuestion},
+	})
+	fakes := newFakeRepos()
+	s := newTestInterviewsService(t, testConfig(t), repo, fakes)
+
+	for _, q := range []repository.MemoryQuestion{projectQuestion, conflictQuestion} {
+		if err := s.AddVideoToQuestion(context.Background(), q.PublicID, publicID, "videos/"+q.PublicID[len(q.PublicID)-2:]+".mp4"); err != nil {
+			t.Fatalf("AddVideoToQuestion() error = %v", err)
+		}
+	}
+	interview, err := s.CreateInterviewResult(context.Background(), publicID, "", false)
+	if err != nil {
+		t.Fatalf("CreateInterviewResult() error = %v", err)
+	}
+	if interview.Status != models.InterviewStatusEvaluated || interview.Result.Score != 7 {
+		t.Errorf("interview = %s with score %d, want evaluated with score 7", interview.Status, interview.Result.Score)
+	}
+	want := []string{models.InterviewStatusSubmitted, models.InterviewStatusEvaluated}
+	if got := statuses(t, repo, publicID); !equalStrings(got, want) {
+		t.Errorf("status transitions = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+type invitationsService struct {
+	cfg            *config.Configs
+	logger         *zap.SugaredLogger
+	invitationRepo repository.InvitationRepository
+	interviewRepo  repository.InterviewRepository
+	tx             repository.TxManager
+	interviews     *interviewsService
+	notifications  *notificationsService
+}
+
+func NewInvitationsService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, interviews *interviewsService, notifications *notificationsService) *invitationsService {
+	return &invitationsService{
+		invitationRepo: repo.InvitationRepository,
+		interviewRepo:  repo.InterviewRepository,
+		tx:             repo.TxManager,
+		interviews:     interviews,
+		notifications:  notifications,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+func (s *invitationsService) CreateInvitation(positionPublicID, candidatePublicID string) (*models.Invitation, error) {
+	nonce, err := newNonce()
+	if err != nil {
+		return nil, err
+	}
+	publicID, err := s.invitationRepo.CreateInvitation(positionPublicID, candidatePublicID, nonce, time.Now().Add(s.cfg.Invitation.TTL))
+	if err != nil {
+		return nil, err
+	}
+	return s.send(publicID)
+}
+
+func (s *invitationsService) GetPositionInvitations(positionPublicID string) ([]*models.Invitation, error) {
+	return s.invitationRepo.GetPositionInvitations(positionPublicID)
+}
+
+func (s *invitationsService) ResendInvitation(publicID string) (*models.Invitation, error) {
+	nonce, err := newNonce()
+	if err != nil {
+		return nil, err
+	}
+	resent, err := s.invitationRepo.ResendInvitation(publicID, nonce, time.Now().Add(s.cfg.Invitation.TTL))
+	if err != nil {
+		return nil, err
+	}
+	if !resent {
+		if _, err = s.invitationRepo.GetInvitation(publicID); err != nil {
+			return nil, err
+		}
+		return nil, models.ErrInvitationClosed
+	}
+	return s.send(publicID)
+}
+
+func (s *invitationsService) RevokeInvitation(ctx context.Context, publicID string) error {
+	invitation, err := s.invitationRepo.GetInvitation(publicID)
+	if err != nil {
+		return err
+	}
+	revoked, err := s.invitationRepo.TransitionInvitation(ctx, publicID, "",
+		[]string{models.InvitationStatusSent, models.InvitationStatusOpened}, models.InvitationStatusRevoked)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return models.ErrInvitationClosed
+	}
+	_, err = s.interviewRepo.TransitionInterview(ctx, invitation.InterviewPublicID,
+		[]string{models.InterviewStatusPending}, models.InterviewStatusExpired)
+	return err
+}
+
+// OpenInvitation records that the candidate followed the link. The token
+// stays valid until the interview is started with AcceptInvitation.
+func (s *invitationsService) OpenInvitation(ctx context.Context, token string) (*models.Invitation, error) {
+	invitation, err := s.invitationByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.Status == models.InvitationStatusSent {
+		_, err = s.invitationRepo.TransitionInvitation(ctx, invitation.PublicID, invitation.Nonce,
+			[]string{models.InvitationStatusSent}, models.InvitationStatusOpened)
+		if err != nil {
+			return nil, err
+		}
+		return s.invitationRepo.GetInvitation(invitation.PublicID)
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation consumes the token and starts the interview in the same
+// transaction, so that a failed start leaves the token valid. The session
+// returned carries the token the candidate takes the interview with.
+func (s *invitationsService) AcceptInvitation(ctx context.Context, token string) (*models.InterviewSession, error) {
+	invitation, err := s.invitationByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	var session *models.InterviewSession
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		started, err := s.invitationRepo.TransitionInvitation(ctx, invitation.PublicID, invitation.Nonce,
+			[]string{models.InvitationStatusSent, models.InvitationStatusOpened}, models.InvitationStatusStarted)
+		if err != nil {
+			return err
+		}
+		if !started {
+			return models.ErrInvitationClosed
+		}
+		session, err = s.interviews.StartInterview(ctx, invitation.InterviewPublicID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.cfg.Interview.Duration)
+	if session.Deadline != nil {
+		expiresAt = *session.Deadline
+	}
+	session.Token = signSessionToken(s.cfg.Token.TokenSecret, session.PublicID, expiresAt.Add(s.cfg.Interview.Grace))
+	return session, nil
+}
+
+func (s *invitationsService) invitationByToken(token string) (*models.Invitation, error) {
+	publicID, nonce, err := parseInvitationToken(s.cfg.Token.TokenSecret, token)
+	if err != nil {
+		return nil, err
+	}
+	invitation, err := s.invitationRepo.GetInvitation(publicID)
+	if err != nil {
+		if errors.Is(err, models.ErrInvitationNotFound) {
+			return nil, models.ErrInvalidToken
+		}
+		return nil, err
+	}
+	if !hmac.Equal([]byte(invitation.Nonce), []byte(nonce)) {
+		return nil, models.ErrInvalidToken
+	}
+	switch invitation.Status {
+	case models.InvitationStatusSent, models.InvitationStatusOpened:
+		return invitation, nil
+	case models.InvitationStatusExpired:
+		return nil, models.ErrInvitationExpired
+	default:
+		return nil, models.ErrInvitationClosed
+	}
+}
+
+// send emails a freshly issued token to the candidate.
+func (s *invitationsService) send(publicID string) (*models.Invitation, error) {
+	invitation, err := s.withToken(publicID)
+	if err != nil {
+		return nil, err
+	}
+	s.notifications.notifyAsync(&models.Notification{
+		Event:             models.NotificationInvitation,
+		InterviewPublicID: invitation.InterviewPublicID,
+		Link:              invitation.Link,
+		ExpiresAt:         &invitation.ExpiresAt,
+	})
+	return invitation, nil
+}
+
+func (s *invitationsService) withToken(publicID string) (*models.Invitation, error) {
+	invitation, err := s.invitationRepo.GetInvitation(publicID)
+	if err != nil {
+		return nil, err
+	}
+	invitation.Token = signInvitationToken(s.cfg.Token.TokenSecret, invitation)
+	invitation.Link = s.cfg.Invitation.LinkBaseURL + invitation.Token
+	return invitation, nil
+}
+
+// Invitation tokens have the form <public id>.<nonce>.<expiry>.<signature>,
+// signed with HMAC-SHA256. Resending rotates the nonce, which invalidates
+// every previously issued token.
+func signInvitationToken(secret string, invitation *models.Invitation) string {
+	payload := strings.Join([]string{invitation.PublicID, invitation.Nonce, strconv.FormatInt(invitation.ExpiresAt.Unix(), 10)}, ".")
+	return payload + "." + invitationSignature(secret, payload)
+}
+
+func parseInvitationToken(secret, token string) (string, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return "", "", models.ErrInvalidToken
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(invitationSignature(secret, payload)), []byte(parts[3])) {
+		return "", "", models.ErrInvalidToken
+	}
+	expiresAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", "", models.ErrInvalidToken
+	}
+	if time.Now().Unix() > expiresAt {
+		return "", "", models.ErrInvitationExpired
+	}
+	return parts[0], parts[1], nil
+}
+
+func invitationSignature(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
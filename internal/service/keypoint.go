@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/analytics"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+type keyPointsService struct {
+	cfg          *config.Configs
+	logger       *zap.SugaredLogger
+	keyPointRepo repository.KeyPointRepository
+}
+
+func NewKeyPointsService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger) *keyPointsService {
+	return &keyPointsService{
+		keyPointRepo: repo.KeyPointRepository,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+func (s *keyPointsService) GetKeyPoints(ctx context.Context, questionPublicID string) ([]*models.KeyPoint, error) {
+	points, err := s.keyPointRepo.GetKeyPoints(ctx, questionPublicID)
+	if err != nil {
+		return nil, err
+	}
+	if points[questionPublicID] == nil {
+		return make([]*models.KeyPoint, 0), nil
+	}
+	return points[questionPublicID], nil
+}
+
+func (s *keyPointsService) SetKeyPoints(questionPublicID string, points []*models.KeyPoint) ([]*models.KeyPoint, error) {
+	for _, point := range points {
+		if point.Weight == 0 {
+			point.Weight = 1
+		}
+	}
+	if err := s.keyPointRepo.SetKeyPoints(questionPublicID, points); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// applyCoverage matches every answer of result against the key points of
+// its question. With a positive blend weight the question scores are mixed
+// with coverage, scaled to the score range of contract, and the overall
+// score is recomputed from them.
+func applyCoverage(cfg *config.Coverage, contract *config.AnalyzerContract, result *models.Result, points map[string][]*models.KeyPoint) {
+	blended := false
+	for i := range result.Questions {
+		q := &result.Questions[i]
+		if len(points[q.PublicID]) == 0 {
+			continue
+		}
+		q.Coverage = analytics.Coverage(q.Answer, points[q.PublicID])
+		if cfg.BlendWeight > 0 {
+			score := q.Score
+			q.AnalyzerScore = &score
+			covered := float64(contract.MinScore) + q.Coverage.Coverage*float64(contract.MaxScore-contract.MinScore)
+			q.Score = int(math.Round((1-cfg.BlendWeight)*float64(score) + cfg.BlendWeight*covered))
+			blended = true
+		}
+	}
+	if blended {
+		total := 0
+		for _, q := range result.Questions {
+			total += q.Score
+		}
+		result.Score = total / len(result.Questions)
+	}
+}
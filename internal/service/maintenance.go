@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+type maintenanceService struct {
+	cfg            *config.Configs
+	logger         *zap.SugaredLogger
+	invitationRepo repository.InvitationRepository
+	interviewRepo  repository.InterviewRepository
+	interviews     *interviewsService
+	invitations    *invitationsService
+	notifications  *notificationsService
+}
+
+func NewMaintenanceService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, interviews *interviewsService, invitations *invitationsService, notifications *notificationsService) *maintenanceService {
+	return &maintenanceService{
+		invitationRepo: repo.InvitationRepository,
+		interviewRepo:  repo.InterviewRepository,
+		interviews:     interviews,
+		invitations:    invitations,
+		notifications:  notifications,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+// RemindInvitations reminds candidates of invitations that are about to
+// expire. Every invitation is reminded at most once per issued link; one
+// whose reminder could not be sent is tried again on the next run.
+func (s *maintenanceService) RemindInvitations() (int, error) {
+	publicIDs, err := s.invitationRepo.ClaimInvitationReminders(time.Now().Add(s.cfg.Scheduler.InvitationReminder))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var sendErr error
+	for _, publicID := range publicIDs {
+		invitation, err := s.invitations.withToken(publicID)
+		if err == nil {
+			err = s.notifications.Notify(&models.Notification{
+				Event:             models.NotificationReminder,
+				InterviewPublicID: invitation.InterviewPublicID,
+				Link:              invitation.Link,
+				ExpiresAt:         &invitation.ExpiresAt,
+			})
+		}
+		if err != nil {
+			s.logger.Errorf("could not remind invitation %s: %v", publicID, err)
+			sendErr = err
+			if err = s.invitationRepo.ReleaseInvitationReminder(publicID); err != nil {
+				s.logger.Errorf("could not release reminder of invitation %s: %v", publicID, err)
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, sendErr
+}
+
+func (s *maintenanceService) ExpireInvitations() (int, error) {
+	return s.invitationRepo.ExpireInvitations()
+}
+
+// RemindInterviews reminds candidates of started interviews that are close
+// to their deadline, with a link to continue the session. An interview
+// whose reminder could not be sent is tried again on the next run.
+func (s *maintenanceService) RemindInterviews() (int, error) {
+	ctx := context.Background()
+	sessions, err := s.interviewRepo.ClaimDeadlineReminders(ctx, time.Now().Add(s.cfg.Scheduler.InterviewReminder))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var sendErr error
+	for _, session := range sessions {
+		notification := &models.Notification{
+			Event:             models.NotificationReminder,
+			InterviewPublicID: session.PublicID,
+			ExpiresAt:         session.Deadline,
+		}
+		if s.cfg.Interview.LinkBaseURL != "" && session.Deadline != nil {
+			token := signSessionToken(s.cfg.Token.TokenSecret, session.PublicID, session.Deadline.Add(s.cfg.Interview.Grace))
+			notification.Link = s.cfg.Interview.LinkBaseURL + token
+		}
+		if err = s.notifications.Notify(notification); err != nil {
+			s.logger.Errorf("could not remind interview %s: %v", session.PublicID, err)
+			sendErr = err
+			if err = s.interviewRepo.ReleaseDeadlineReminder(ctx, session.PublicID); err != nil {
+				s.logger.Errorf("could not release reminder of interview %s: %v", session.PublicID, err)
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, sendErr
+}
+
+// ExpireInterviews closes the interviews still in progress after their
+// deadline and grace period, which the candidate abandoned. Those with
+// answers are submitted and analyzed; the others are expired.
+func (s *maintenanceService) ExpireInterviews() (int, error) {
+	ctx := context.Background()
+	deadlineBefore := time.Now().Add(-s.cfg.Interview.Grace)
+	submitted, err := s.interviewRepo.SubmitOverdueInterviews(ctx, deadlineBefore)
+	if err != nil {
+		return 0, err
+	}
+	for _, publicID := range submitted {
+		s.interviews.analyzeSubmitted(ctx, publicID)
+	}
+	expired, err := s.interviewRepo.ExpireInterviews(ctx, deadlineBefore)
+	return len(submitted) + expired, err
+}
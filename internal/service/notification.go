@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/notification"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+type notificationsService struct {
+	cfg              *config.Configs
+	logger           *zap.SugaredLogger
+	notificationRepo repository.NotificationRepository
+	notifier         notification.Notifier
+	templates        *notification.Templates
+	background       *background
+}
+
+func NewNotificationsService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, notifier notification.Notifier, bg *background) (*notificationsService, error) {
+	templates, err := notification.NewTemplates()
+	if err != nil {
+		return nil, err
+	}
+	return &notificationsService{
+		notificationRepo: repo.NotificationRepository,
+		notifier:         notifier,
+		templates:        templates,
+		background:       bg,
+		cfg:              cfg,
+		logger:           logger,
+	}, nil
+}
+
+// Notify emails the candidate about invitations and reminders and the
+// recruiter about submitted interviews and ready results. Every attempt is
+// recorded, successful or not.
+func (s *notificationsService) Notify(n *models.Notification) error {
+	nc, err := s.notificationRepo.GetNotificationContext(n.InterviewPublicID)
+	if err != nil {
+		return err
+	}
+
+	recipient := nc.RecruiterEmail
+	if n.Event == models.NotificationInvitation || n.Event == models.NotificationReminder {
+		recipient = nc.CandidateEmail
+	}
+	if recipient == "" {
+		return fmt.Errorf("no recipient for %s email of interview %s", n.Event, n.InterviewPublicID)
+	}
+
+	subject, body, err := s.templates.Render(n.Event, &notification.TemplateData{
+		CompanyName:       nc.CompanyName,
+		CompanyLogo:       nc.CompanyLogo,
+		CandidateName:     nc.CandidateName,
+		RecruiterName:     nc.RecruiterName,
+		PositionName:      nc.PositionName,
+		InterviewPublicID: nc.InterviewPublicID,
+		Link:              n.Link,
+		ExpiresAt:         n.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	delivery := &models.EmailDelivery{
+		InterviewPublicID: n.InterviewPublicID,
+		Event:             n.Event,
+		Recipient:         recipient,
+		Subject:           subject,
+		Status:            models.DeliveryStatusSent,
+	}
+	sendErr := s.notifier.Send(&notification.Message{
+		From:    s.cfg.SMTP.From,
+		To:      recipient,
+		Subject: subject,
+		HTML:    body,
+	})
+	if sendErr != nil {
+		delivery.Status = models.DeliveryStatusFailed
+		delivery.Error = sendErr.Error()
+	}
+	if err = s.notificationRepo.AddDelivery(delivery); err != nil {
+		s.logger.Errorf("could not record %s email of interview %s: %v", n.Event, n.InterviewPublicID, err)
+	}
+	return sendErr
+}
+
+// NotifyInterviewEvent emails the recruiter when an interview is submitted
+// and when its results are ready.
+func (s *notificationsService) NotifyInterviewEvent(event *models.OutboxEvent) error {
+	switch event.Event {
+	case models.OutboxInterviewEventPrefix + models.InterviewStatusSubmitted:
+		return s.Notify(&models.Notification{Event: models.NotificationInterviewSubmitted, InterviewPublicID: event.AggregateID})
+	case models.OutboxInterviewEventPrefix + models.InterviewStatusEvaluated:
+		return s.Notify(&models.Notification{Event: models.NotificationResultsReady, InterviewPublicID: event.AggregateID})
+	}
+	return nil
+}
+
+// notifyAsync sends n in the background; failures are only logged since
+// emails must never fail the request that triggered them.
+func (s *notificationsService) notifyAsync(n *models.Notification) {
+	s.background.Go(context.Background(), func(context.Context) {
+		if err := s.Notify(n); err != nil {
+			s.logger.Errorf("could not send %s email of interview %s: %v", n.Event, n.InterviewPublicID, err)
+		}
+	})
+}
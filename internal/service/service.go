@@ -1,22 +1,168 @@
 package service
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/analyzer"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/metrics"
 	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/notification"
 	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
 	"go.uber.org/zap"
 )
 
 type InterviewsService interface {
-	CreateInterviewResult(publicID string) (*models.InterviewResults, error)
-	AddVideoToQuestion(questionPublicID, interviewPublicID, video string) error
-	GetAllInterviews() ([]*models.InterviewResults, error)
-	GetInterviewByPublicID(publicID string) (*models.InterviewResults, error)
+	CreateInterviewResult(ctx context.Context, publicID, idempotencyKey string, reanalyze bool) (*models.InterviewResults, error)
+	AddVideoToQuestion(ctx context.Context, questionPublicID, interviewPublicID, video string) error
+	GetAllInterviews(ctx context.Context) ([]*models.InterviewResults, error)
+	GetInterviewByPublicID(ctx context.Context, publicID string) (*models.InterviewResults, error)
+	AuthorizeSession(publicID, token string) error
+	StartInterview(ctx context.Context, publicID string) (*models.InterviewSession, error)
+	GetNextQuestion(ctx context.Context, publicID string) (*models.SessionQuestion, error)
+	StartQuestion(ctx context.Context, publicID, questionPublicID string) (*models.SessionQuestion, error)
+	SubmitAnswer(ctx context.Context, publicID, questionPublicID, video string) error
+	OverrideScore(ctx context.Context, publicID string, score int, reason string) (*models.InterviewResults, error)
+	GetProgress(ctx context.Context, publicID string) (*models.ProgressEvent, error)
+	ReanalyzeQuestion(ctx context.Context, publicID, questionPublicID string) (*models.InterviewResults, error)
+	GetQuestionAnalyses(ctx context.Context, publicID string) ([]*models.QuestionAnalysis, error)
+	GetResultVersions(ctx context.Context, publicID string) ([]*models.ResultVersion, error)
+	GetAnalyzerQueue() *models.AnalyzerQueue
+}
+
+type TranscriptsService interface {
+	SearchTranscripts(companyPublicID, language string, args *models.SearchArgs) (*models.TranscriptSearchResult, error)
+}
+
+type KeyPointsService interface {
+	GetKeyPoints(ctx context.Context, questionPublicID string) ([]*models.KeyPoint, error)
+	SetKeyPoints(questionPublicID string, points []*models.KeyPoint) ([]*models.KeyPoint, error)
+}
+
+type SimilaritiesService interface {
+	GetPositionSimilarities(positionPublicID string) ([]*models.AnswerSimilarity, error)
+}
+
+type IntegrityService interface {
+	AddIntegrityEvents(interviewPublicID string, events []*models.IntegrityEvent) error
+}
+
+type InvitationsService interface {
+	CreateInvitation(positionPublicID, candidatePublicID string) (*models.Invitation, error)
+	GetPositionInvitations(positionPublicID string) ([]*models.Invitation, error)
+	ResendInvitation(publicID string) (*models.Invitation, error)
+	RevokeInvitation(ctx context.Context, publicID string) error
+	OpenInvitation(ctx context.Context, token string) (*models.Invitation, error)
+	AcceptInvitation(ctx context.Context, token string) (*models.InterviewSession, error)
+}
+
+type MaintenanceService interface {
+	RemindInvitations() (int, error)
+	ExpireInvitations() (int, error)
+	RemindInterviews() (int, error)
+	ExpireInterviews() (int, error)
 }
+
+type WebhooksService interface {
+	CreateWebhookEndpoint(companyPublicID, url string, events []string) (*models.WebhookEndpoint, error)
+	GetCompanyWebhookEndpoints(companyPublicID string) ([]*models.WebhookEndpoint, error)
+	DeleteWebhookEndpoint(publicID string) error
+	GetWebhookDeliveries(endpointPublicID string, args *models.SearchArgs) ([]*models.WebhookDelivery, error)
+	GetWebhookDelivery(publicID string) (*models.WebhookDelivery, error)
+	ReplayWebhookDelivery(publicID string) (*models.WebhookDelivery, error)
+	DeliverWebhooks() (int, error)
+	PublishInterviewEvent(event *models.OutboxEvent) error
+}
+
+type DeadLettersService interface {
+	GetFailedAnalyses(status string, args *models.SearchArgs) ([]*models.FailedAnalysis, error)
+	GetFailedAnalysis(publicID string) (*models.FailedAnalysis, error)
+	RetryFailedAnalyses(publicIDs []string) ([]*models.FailedAnalysis, error)
+	DiscardFailedAnalyses(publicIDs []string) (int, error)
+}
+
+type NotificationsService interface {
+	NotifyInterviewEvent(event *models.OutboxEvent) error
+}
+
 type Service struct {
 	InterviewsService
+	TranscriptsService
+	KeyPointsService
+	SimilaritiesService
+	IntegrityService
+	InvitationsService
+	MaintenanceService
+	WebhooksService
+	NotificationsService
+	DeadLettersService
+
+	background *background
+}
+
+// New returns the services; the work they run in the background, such as
+// analyses and emails, lasts until ctx is cancelled and Wait returns.
+func New(ctx context.Context, repos *repository.Repository, log *zap.SugaredLogger, cfg *config.Configs, notifier notification.Notifier, m *metrics.Metrics) (*Service, error) {
+	if w := cfg.Coverage.BlendWeight; w < 0 || w > 1 {
+		return nil, fmt.Errorf("invalid coverage blend weight %v, want it within 0 to 1", w)
+	}
+	bg := newBackground(ctx)
+	notifications, err := NewNotificationsService(repos, cfg, log, notifier, bg)
+	if err != nil {
+		return nil, err
+	}
+	similarities := NewSimilaritiesService(repos, cfg, log)
+	webhooks := NewWebhooksService(repos, cfg, log, bg)
+	client, err := newAnalyzer(cfg, m)
+	if err != nil {
+		return nil, err
+	}
+	interviews := NewInterviewsService(repos, cfg, log, similarities, client, analyzer.NewLimiter(cfg.Analysis.Limits), m, bg)
+	invitations := NewInvitationsService(repos, cfg, log, interviews, notifications)
+	return &Service{
+		background:           bg,
+		InterviewsService:    interviews,
+		InvitationsService:   invitations,
+		MaintenanceService:   NewMaintenanceService(repos, cfg, log, interviews, invitations, notifications),
+		WebhooksService:      webhooks,
+		NotificationsService: notifications,
+		DeadLettersService:   NewDeadLettersService(repos, cfg, log, interviews, bg),
+		SimilaritiesService:  similarities,
+		IntegrityService:     NewIntegrityService(repos, cfg, log),
+		TranscriptsService:   NewTranscriptsService(repos, cfg, log),
+		KeyPointsService:     NewKeyPointsService(repos, cfg, log),
+	}, nil
+}
+
+// Wait waits for the work running in the background once the context the
+// services were created with is cancelled.
+func (s *Service) Wait() {
+	s.background.Wait()
 }
 
-func New(repos *repository.Repository, log *zap.SugaredLogger, cfg *config.Configs) *Service {
-	return &Service{InterviewsService: NewInterviewsService(repos, cfg, log)}
+// newAnalyzer returns the analyzer client, recording or replaying its
+// calls as configured, with its responses validated and its calls measured.
+func newAnalyzer(cfg *config.Configs, m *metrics.Metrics) (analyzer.Client, error) {
+	var (
+		client analyzer.Client
+		err    error
+	)
+	switch fixtures := cfg.Analysis.Fixtures; fixtures.Mode {
+	case "":
+		client = analyzer.New(cfg.Video)
+	case "record":
+		client, err = analyzer.NewRecorder(analyzer.New(cfg.Video), fixtures.Dir)
+	case "replay":
+		client, err = analyzer.NewReplayer(fixtures.Dir)
+	default:
+		err = fmt.Errorf("unknown analyzer fixtures mode %q", fixtures.Mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if client, err = analyzer.NewValidating(client, cfg.Analysis.Contract); err != nil {
+		return nil, err
+	}
+	return analyzer.NewObserved(client, m.ObserveAnalyzerCall), nil
 }
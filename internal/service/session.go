@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+)
+
+// statuses an interview may be in when its result is (re)created; one the
+// candidate is still taking is not analyzed
+var resultStatuses = []string{
+	models.InterviewStatusSubmitted,
+	models.InterviewStatusEvaluated,
+	models.InterviewStatusFailed,
+}
+
+// statuses an interview may be in when its analysis fails; an evaluated
+// interview keeps its result
+var unanalyzedStatuses = []string{
+	models.InterviewStatusSubmitted,
+	models.InterviewStatusFailed,
+}
+
+func (s *interviewsService) StartInterview(ctx context.Context, publicID string) (_ *models.InterviewSession, err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.StartInterview", publicID)
+	defer func() { endSpan(span, err) }()
+
+	session, err := s.interviewRepo.GetSession(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status == models.InterviewStatusPending {
+		_, err = s.interviewRepo.StartInterview(ctx, publicID, time.Now().Add(s.cfg.Interview.Duration))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.activeSession(ctx, publicID)
+}
+
+// GetNextQuestion returns the question to answer next. Once none is left
+// the interview is submitted and it fails with models.ErrInterviewSubmitted.
+func (s *interviewsService) GetNextQuestion(ctx context.Context, publicID string) (_ *models.SessionQuestion, err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.GetNextQuestion", publicID)
+	defer func() { endSpan(span, err) }()
+
+	if _, err := s.activeSession(ctx, publicID); err != nil {
+		return nil, err
+	}
+	questions, err := s.sessionQuestions(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+
+	next := s.nextQuestion(questions, time.Now())
+	if next == nil {
+		if err = s.submit(ctx, publicID); err != nil {
+			return nil, err
+		}
+		return nil, models.ErrInterviewSubmitted
+	}
+	return next, nil
+}
+
+func (s *interviewsService) StartQuestion(ctx context.Context, publicID, questionPublicID string) (_ *models.SessionQuestion, err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.StartQuestion", publicID)
+	defer func() { endSpan(span, err) }()
+
+	session, err := s.activeSession(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	questions, err := s.sessionQuestions(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	next := s.nextQuestion(questions, now)
+	if next == nil || next.PublicID != questionPublicID {
+		if findQuestion(questions, questionPublicID) == nil {
+			return nil, models.ErrQuestionNotFound
+		}
+		return nil, models.ErrQuestionOutOfOrder
+	}
+	if next.StartedAt != nil {
+		return next, nil
+	}
+
+	deadline := now.Add(time.Duration(next.TimeLimit) * time.Second)
+	if session.Deadline != nil && session.Deadline.Before(deadline) {
+		deadline = *session.Deadline
+	}
+	if err = s.interviewRepo.StartQuestion(ctx, publicID, questionPublicID, deadline); err != nil {
+		return nil, err
+	}
+	next.StartedAt, next.Deadline = &now, &deadline
+	return next, nil
+}
+
+// SubmitAnswer records the answer and, when it is the last one, submits the
+// interview in the same transaction.
+func (s *interviewsService) SubmitAnswer(ctx context.Context, publicID, questionPublicID, video string) (err error) {
+	ctx, span := startSpan(ctx, "InterviewsService.SubmitAnswer", publicID)
+	defer func() { endSpan(span, err) }()
+
+	if _, err := s.activeSession(ctx, publicID); err != nil {
+		return err
+	}
+
+	var submitted bool
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		submitted = false
+		questions, err := s.sessionQuestions(ctx, publicID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		q := findQuestion(questions, questionPublicID)
+		switch {
+		case q == nil:
+			return models.ErrQuestionNotFound
+		case q.StartedAt == nil:
+			return models.ErrQuestionNotStarted
+		case q.AnsweredAt != nil:
+			return models.ErrQuestionAnswered
+		case now.After(q.Deadline.Add(s.cfg.Interview.Grace)):
+			return models.ErrDeadlineExceeded
+		}
+
+		if err = s.interviewRepo.AnswerQuestion(ctx, publicID, questionPublicID, video); err != nil {
+			return err
+		}
+		q.AnsweredAt = &now
+
+		if s.nextQuestion(questions, now) != nil {
+			return nil
+		}
+		submitted, err = s.interviewRepo.TransitionInterview(ctx, publicID, []string{models.InterviewStatusInProgress}, models.InterviewStatusSubmitted)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if submitted {
+		s.analyzeSubmitted(ctx, publicID)
+	}
+	return nil
+}
+
+// activeSession returns the session if it still accepts answers. Sessions
+// past their overall deadline are expired.
+func (s *interviewsService) activeSession(ctx context.Context, publicID string) (*models.InterviewSession, error) {
+	session, err := s.interviewRepo.GetSession(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	switch session.Status {
+	case models.InterviewStatusPending:
+		return nil, models.ErrInterviewNotStarted
+	case models.InterviewStatusInProgress:
+	default:
+		return nil, models.ErrInterviewClosed
+	}
+
+	if session.Deadline != nil && time.Now().After(session.Deadline.Add(s.cfg.Interview.Grace)) {
+		_, err = s.interviewRepo.TransitionInterview(ctx, publicID, []string{models.InterviewStatusInProgress}, models.InterviewStatusExpired)
+		if err != nil {
+			return nil, err
+		}
+		return nil, models.ErrDeadlineExceeded
+	}
+	return session, nil
+}
+
+func (s *interviewsService) sessionQuestions(ctx context.Context, publicID string) ([]*models.SessionQuestion, error) {
+	questions, err := s.interviewRepo.GetSessionQuestions(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range questions {
+		if q.TimeLimit <= 0 {
+			q.TimeLimit = int(s.cfg.Interview.QuestionTimeLimit.Seconds())
+		}
+	}
+	return questions, nil
+}
+
+// nextQuestion returns the first question that is neither answered nor
+// timed out. Timed out questions are skipped.
+func (s *interviewsService) nextQuestion(questions []*models.SessionQuestion, now time.Time) *models.SessionQuestion {
+	for _, q := range questions {
+		if q.AnsweredAt != nil {
+			continue
+		}
+		if q.StartedAt != nil && now.After(q.Deadline.Add(s.cfg.Interview.Grace)) {
+			continue
+		}
+		return q
+	}
+	return nil
+}
+
+// submit closes the session and starts the analysis in the background.
+func (s *interviewsService) submit(ctx context.Context, publicID string) error {
+	submitted, err := s.interviewRepo.TransitionInterview(ctx, publicID, []string{models.InterviewStatusInProgress}, models.InterviewStatusSubmitted)
+	if err != nil {
+		return err
+	}
+	if submitted {
+		s.analyzeSubmitted(ctx, publicID)
+	}
+	return nil
+}
+
+// analyzeSubmitted analyzes the submitted interview in the background; the
+// analysis outlives the request submitting it but stays in its trace. No
+// client retries an analysis rejected for backpressure or cut short by
+// the shutdown here, so it is recorded as failed for the admins to retry.
+func (s *interviewsService) analyzeSubmitted(ctx context.Context, publicID string) {
+	s.background.Go(ctx, func(ctx context.Context) {
+		_, err := s.CreateInterviewResult(ctx, publicID, "", false)
+		if err == nil {
+			return
+		}
+		s.logger.Errorf("analysis of submitted interview %s failed: %v", publicID, err)
+		if errors.Is(err, models.ErrAnalyzerBusy) || errors.Is(err, models.ErrAnalysisRateLimited) || ctx.Err() != nil {
+			if err = s.deadLetterRepo.RecordFailedAnalysis(detach(ctx), failedAnalysis(publicID, err)); err != nil {
+				s.logger.Errorf("could not record failed analysis of interview %s: %v", publicID, err)
+			}
+		}
+	})
+}
+
+// AuthorizeSession checks that token was issued for taking the interview
+// when its invitation was accepted and is not past the deadline.
+func (s *interviewsService) AuthorizeSession(publicID, token string) error {
+	tokenPublicID, err := parseSessionToken(s.cfg.Token.TokenSecret, token)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(tokenPublicID), []byte(publicID)) {
+		return models.ErrInvalidToken
+	}
+	return nil
+}
+
+// Session tokens have the form <interview public id>.<expiry>.<signature>,
+// signed with HMAC-SHA256 under a prefix that sets them apart from
+// invitation tokens.
+func signSessionToken(secret, publicID string, expiresAt time.Time) string {
+	payload := publicID + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + invitationSignature(secret, "session:"+payload)
+}
+
+func parseSessionToken(secret, token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", models.ErrInvalidToken
+	}
+	payload := strings.Join(parts[:2], ".")
+	if !hmac.Equal([]byte(invitationSignature(secret, "session:"+payload)), []byte(parts[2])) {
+		return "", models.ErrInvalidToken
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return "", models.ErrInvalidToken
+	}
+	return parts[0], nil
+}
+
+func hasStatus(status string, statuses []string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func findQuestion(questions []*models.SessionQuestion, publicID string) *models.SessionQuestion {
+	for _, q := range questions {
+		if q.PublicID == publicID {
+			return q
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/analytics"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+type similaritiesService struct {
+	cfg            *config.Configs
+	logger         *zap.SugaredLogger
+	transcriptRepo repository.TranscriptRepository
+	similarityRepo repository.SimilarityRepository
+}
+
+func NewSimilaritiesService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger) *similaritiesService {
+	return &similaritiesService{
+		transcriptRepo: repo.TranscriptRepository,
+		similarityRepo: repo.SimilarityRepository,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+func (s *similaritiesService) GetPositionSimilarities(positionPublicID string) ([]*models.AnswerSimilarity, error) {
+	return s.similarityRepo.GetPositionSimilarities(positionPublicID)
+}
+
+// CheckInterview compares the transcripts of a freshly stored interview
+// with the answers to the same questions in other interviews for the
+// position and replaces the flagged pairs of that interview.
+func (s *similaritiesService) CheckInterview(ctx context.Context, interviewPublicID string, transcripts []*models.Transcript) error {
+	questionIDs := make([]string, 0, len(transcripts))
+	for _, t := range transcripts {
+		questionIDs = append(questionIDs, t.QuestionPublicID)
+	}
+	others, err := s.transcriptRepo.GetPositionTranscripts(ctx, interviewPublicID, questionIDs)
+	if err != nil {
+		return err
+	}
+
+	byQuestion := make(map[string][]*models.Transcript)
+	for _, other := range others {
+		byQuestion[other.QuestionPublicID] = append(byQuestion[other.QuestionPublicID], other)
+	}
+
+	flagged := make([]*models.AnswerSimilarity, 0)
+	for _, t := range transcripts {
+		for _, other := range byQuestion[t.QuestionPublicID] {
+			score, fragments := analytics.Similarity(t.Answer, other.Answer, s.cfg.Similarity.ShingleSize)
+			if score < s.cfg.Similarity.Threshold {
+				continue
+			}
+			flagged = append(flagged, &models.AnswerSimilarity{
+				QuestionPublicID:       t.QuestionPublicID,
+				InterviewPublicID:      interviewPublicID,
+				OtherInterviewPublicID: other.InterviewPublicID,
+				Score:                  score,
+				Fragments:              fragments,
+			})
+		}
+	}
+	if len(flagged) != 0 {
+		s.logger.Warnf("interview %s has %d answers similar to other candidates", interviewPublicID, len(flagged))
+	}
+	return s.similarityRepo.ReplaceSimilarities(ctx, interviewPublicID, flagged)
+}
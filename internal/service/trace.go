@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/analyzer"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/Zhiyenbek/sp-interview-main-service/internal/service")
+
+// startSpan starts the span of a method working on the interview.
+func startSpan(ctx context.Context, name, publicID string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attribute.String("interview.public_id", publicID)))
+}
+
+// endSpan ends span, marking it failed with err.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// detach returns a context that stays in the trace of ctx but is not
+// cancelled with it, for recording the outcome of work ctx cut short.
+func detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+}
+
+// acquire waits for a slot of the limiter, traced as the time spent in the
+// queue of the analyzer.
+func (s *interviewsService) acquire(ctx context.Context, ticket analyzer.Ticket) (func(), error) {
+	_, span := tracer.Start(ctx, "analyzer queue", trace.WithAttributes(
+		attribute.String("analyzer.company", ticket.Company),
+		attribute.Int("analyzer.priority", ticket.Priority),
+	))
+	release, err := s.limiter.Acquire(ctx, ticket)
+	endSpan(span, err)
+	return release, err
+}
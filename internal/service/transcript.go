@@ -0,0 +1,69 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+type transcriptsService struct {
+	cfg            *config.Configs
+	logger         *zap.SugaredLogger
+	transcriptRepo repository.TranscriptRepository
+}
+
+func NewTranscriptsService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger) *transcriptsService {
+	return &transcriptsService{
+		transcriptRepo: repo.TranscriptRepository,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+func (s *transcriptsService) SearchTranscripts(companyPublicID, language string, args *models.SearchArgs) (*models.TranscriptSearchResult, error) {
+	if language == "" {
+		language = s.cfg.Search.DefaultLanguage
+	}
+	if strings.TrimSpace(args.Search) == "" || !isSupportedLanguage(s.cfg.Search, language) {
+		return nil, models.ErrInvalidInput
+	}
+	return s.transcriptRepo.SearchTranscripts(companyPublicID, language, args)
+}
+
+func transcriptsFromResult(cfg *config.Search, interviewPublicID string, result *models.Result) []*models.Transcript {
+	transcripts := make([]*models.Transcript, 0, len(result.Questions))
+	for _, q := range result.Questions {
+		if strings.TrimSpace(q.Answer) == "" {
+			continue
+		}
+		transcripts = append(transcripts, &models.Transcript{
+			InterviewPublicID: interviewPublicID,
+			QuestionPublicID:  q.PublicID,
+			Language:          answerLanguage(cfg, &q),
+			Answer:            q.Answer,
+		})
+	}
+	return transcripts
+}
+
+func answerLanguage(cfg *config.Search, q *models.QuestionResult) string {
+	if isSupportedLanguage(cfg, q.Language) {
+		return q.Language
+	}
+	return cfg.DefaultLanguage
+}
+
+func isSupportedLanguage(cfg *config.Search, language string) bool {
+	if language == cfg.DefaultLanguage {
+		return true
+	}
+	for _, l := range cfg.Languages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
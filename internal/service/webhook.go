@@ -0,0 +1,334 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/Zhiyenbek/sp-interview-main-service/config"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/models"
+	"github.com/Zhiyenbek/sp-interview-main-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+type webhooksService struct {
+	cfg           *config.Configs
+	logger        *zap.SugaredLogger
+	webhookRepo   repository.WebhookRepository
+	interviewRepo repository.InterviewRepository
+	client        *http.Client
+	background    *background
+}
+
+func NewWebhooksService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, bg *background) *webhooksService {
+	return &webhooksService{
+		webhookRepo:   repo.WebhookRepository,
+		interviewRepo: repo.InterviewRepository,
+		client:        newWebhookClient(cfg.Webhook),
+		background:    bg,
+		cfg:           cfg,
+		logger:        logger,
+	}
+}
+
+// CreateWebhookEndpoint registers an endpoint and returns it with its
+// signing secret. The secret is not returned afterwards.
+func (s *webhooksService) CreateWebhookEndpoint(companyPublicID, endpointURL string, events []string) (*models.WebhookEndpoint, error) {
+	u, err := url.Parse(endpointURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, models.ErrInvalidInput
+	}
+	if err = checkWebhookHost(u.Hostname()); err != nil {
+		s.logger.Warnf("rejected webhook endpoint %s: %v", endpointURL, err)
+		return nil, models.ErrInvalidInput
+	}
+	if len(events) == 0 {
+		return nil, models.ErrInvalidInput
+	}
+	for _, event := range events {
+		if !isWebhookEvent(event) {
+			return nil, models.ErrInvalidInput
+		}
+	}
+
+	secret, err := newNonce()
+	if err != nil {
+		return nil, err
+	}
+	endpoint := &models.WebhookEndpoint{
+		CompanyPublicID: companyPublicID,
+		URL:             endpointURL,
+		Events:          events,
+		Secret:          "whsec_" + secret,
+	}
+	if err = s.webhookRepo.CreateWebhookEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func (s *webhooksService) GetCompanyWebhookEndpoints(companyPublicID string) ([]*models.WebhookEndpoint, error) {
+	return s.webhookRepo.GetCompanyWebhookEndpoints(companyPublicID)
+}
+
+func (s *webhooksService) DeleteWebhookEndpoint(publicID string) error {
+	return s.webhookRepo.DeleteWebhookEndpoint(publicID)
+}
+
+func (s *webhooksService) GetWebhookDeliveries(endpointPublicID string, args *models.SearchArgs) ([]*models.WebhookDelivery, error) {
+	return s.webhookRepo.GetWebhookDeliveries(endpointPublicID, args)
+}
+
+func (s *webhooksService) GetWebhookDelivery(publicID string) (*models.WebhookDelivery, error) {
+	return s.webhookRepo.GetWebhookDelivery(publicID)
+}
+
+// ReplayWebhookDelivery sends the payload of a past delivery again as a new
+// delivery.
+func (s *webhooksService) ReplayWebhookDelivery(publicID string) (*models.WebhookDelivery, error) {
+	replayID, err := s.webhookRepo.ReplayWebhookDelivery(publicID)
+	if err != nil {
+		return nil, err
+	}
+	s.dispatchAsync([]string{replayID})
+	return s.webhookRepo.GetWebhookDelivery(replayID)
+}
+
+// DeliverWebhooks sends the deliveries that are due and reports how many
+// of them succeeded.
+func (s *webhooksService) DeliverWebhooks() (int, error) {
+	deliveries, err := s.webhookRepo.ClaimWebhookDeliveries(nil, s.cfg.Webhook.BatchSize, 2*s.cfg.Webhook.Timeout)
+	if err != nil {
+		return 0, err
+	}
+	return s.deliverAll(deliveries)
+}
+
+// PublishInterviewEvent queues the outbox events that are webhook events
+// for the subscribed endpoints of the interview's company and makes the
+// first attempt right away.
+func (s *webhooksService) PublishInterviewEvent(event *models.OutboxEvent) error {
+	if !isWebhookEvent(event.Event) {
+		return nil
+	}
+	return s.enqueue(event)
+}
+
+// enqueue builds the payload from the state the outbox event recorded, so
+// that a late delivery reports the interview as it was at the event.
+func (s *webhooksService) enqueue(event *models.OutboxEvent) error {
+	var snapshot struct {
+		Status   string                `json:"status"`
+		Score    *int                  `json:"score"`
+		Override *models.ScoreOverride `json:"override"`
+	}
+	if err := json.Unmarshal(event.Payload, &snapshot); err != nil {
+		return err
+	}
+	// the candidate of an interview never changes
+	interview, err := s.interviewRepo.GetInterview(context.Background(), event.AggregateID)
+	if err != nil {
+		return err
+	}
+	data := &models.WebhookInterview{
+		InterviewPublicID: event.AggregateID,
+		CandidatePublicID: interview.CandidatePublicID,
+		Status:            snapshot.Status,
+	}
+	if event.Event == models.WebhookScoreOverridden {
+		// only an evaluated interview is overridden
+		data.Status = models.InterviewStatusEvaluated
+	}
+	if event.Event == models.WebhookInterviewEvaluated || event.Event == models.WebhookScoreOverridden {
+		data.Score = snapshot.Score
+		data.Override = snapshot.Override
+	}
+	payload, err := json.Marshal(&models.WebhookPayload{
+		Event:      event.Event,
+		OccurredAt: event.CreatedAt.UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		return err
+	}
+
+	publicIDs, err := s.webhookRepo.EnqueueWebhookDeliveries(event.Event, event.AggregateID, payload)
+	if err != nil {
+		return err
+	}
+	s.dispatchAsync(publicIDs)
+	return nil
+}
+
+func (s *webhooksService) dispatchAsync(publicIDs []string) {
+	if len(publicIDs) == 0 {
+		return
+	}
+	s.background.Go(context.Background(), func(context.Context) {
+		deliveries, err := s.webhookRepo.ClaimWebhookDeliveries(publicIDs, len(publicIDs), 2*s.cfg.Webhook.Timeout)
+		if err == nil {
+			_, err = s.deliverAll(deliveries)
+		}
+		if err != nil {
+			s.logger.Errorf("could not send webhook deliveries %v: %v", publicIDs, err)
+		}
+	})
+}
+
+// deliverAll attempts every delivery; a delivery whose attempt could not
+// be recorded doesn't hold up the others and is reported in the error.
+func (s *webhooksService) deliverAll(deliveries []*models.WebhookDelivery) (int, error) {
+	delivered := 0
+	var recordErr error
+	for _, d := range deliveries {
+		if err := s.deliver(d); err != nil {
+			s.logger.Errorf("could not record attempt of webhook delivery %s: %v", d.PublicID, err)
+			recordErr = err
+			continue
+		}
+		if d.Status == models.WebhookDeliveryDelivered {
+			delivered++
+		}
+	}
+	return delivered, recordErr
+}
+
+// deliver makes one attempt and schedules the next one on failure.
+// Endpoints are expected to answer with a 2xx status.
+func (s *webhooksService) deliver(d *models.WebhookDelivery) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	attempt := &models.WebhookAttempt{Attempt: d.Attempts + 1}
+
+	started := time.Now()
+	req, err := http.NewRequest(http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Webhook-Event", d.Event)
+		req.Header.Set("X-Webhook-Delivery", d.PublicID)
+		req.Header.Set("X-Webhook-Timestamp", timestamp)
+		req.Header.Set("X-Webhook-Signature", "sha256="+webhookSignature(d.Secret, timestamp, d.Payload))
+		var resp *http.Response
+		if resp, err = s.client.Do(req); err == nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+			code := resp.StatusCode
+			attempt.ResponseCode = &code
+			if code < 200 || code >= 300 {
+				err = fmt.Errorf("unexpected response status %s", resp.Status)
+			}
+		}
+	}
+	attempt.DurationMs = time.Since(started).Milliseconds()
+
+	now := time.Now()
+	d.Attempts = attempt.Attempt
+	d.LastResponseCode = attempt.ResponseCode
+	d.LastError = ""
+	switch {
+	case err == nil:
+		d.Status = models.WebhookDeliveryDelivered
+		d.DeliveredAt = &now
+	case d.Attempts >= s.cfg.Webhook.MaxAttempts:
+		d.Status = models.WebhookDeliveryFailed
+	default:
+		next := now.Add(webhookBackoff(s.cfg.Webhook, d.Attempts))
+		d.NextAttemptAt = &next
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		d.LastError = attempt.Error
+		s.logger.Warnf("webhook delivery %s to %s failed on attempt %d: %v", d.PublicID, d.URL, d.Attempts, err)
+	}
+	return s.webhookRepo.RecordWebhookAttempt(d, attempt)
+}
+
+// newWebhookClient returns the client deliveries are sent with. It refuses
+// to connect to internal addresses, whatever the endpoint host resolves to
+// at the time of the delivery or a redirect leads to.
+func newWebhookClient(cfg *config.Webhook) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: cfg.Timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			return checkWebhookIP(net.ParseIP(host))
+		},
+	}
+	return &http.Client{
+		Timeout: cfg.Timeout,
+		Transport: &http.Transport{
+			DialContext: dialer.DialContext,
+		},
+	}
+}
+
+// checkWebhookHost rejects hosts that resolve to internal addresses.
+func checkWebhookHost(host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		return checkWebhookIP(ip)
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(context.Background(), host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if err = checkWebhookIP(addr.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWebhookIP rejects loopback, private, link-local and other addresses
+// that are not reachable on the internet.
+func checkWebhookIP(ip net.IP) error {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("address %v is not allowed for webhooks", ip)
+	}
+	return nil
+}
+
+// webhookSignature signs "<timestamp>.<body>" so that receivers can reject
+// replayed requests by their timestamp.
+func webhookSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff returns the delay before the attempt following attempt.
+func webhookBackoff(cfg *config.Webhook, attempt int) time.Duration {
+	delay := cfg.BackoffBase
+	for i := 1; i < attempt && delay < cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > cfg.BackoffMax {
+		delay = cfg.BackoffMax
+	}
+	return delay
+}
+
+func isWebhookEvent(event string) bool {
+	for _, e := range models.WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
@@ -35,6 +35,8 @@ CREATE TABLE IF NOT EXISTS companies (
     description TEXT
 );
 
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS plan TEXT NOT NULL DEFAULT 'free';
+
 CREATE TABLE IF NOT EXISTS positions (
     id SERIAL PRIMARY KEY,
     public_id UUID UNIQUE DEFAULT uuid_generate_v4() NOT NULL,
@@ -63,13 +65,40 @@ CREATE TABLE IF NOT EXISTS interviews (
     results JSONB
 );
 
+ALTER TABLE interviews ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'pending';
+ALTER TABLE interviews ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ;
+ALTER TABLE interviews ADD COLUMN IF NOT EXISTS deadline TIMESTAMPTZ;
+ALTER TABLE interviews ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMPTZ;
+ALTER TABLE interviews ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS questions (
+    id SERIAL PRIMARY KEY,
+    public_id UUID UNIQUE DEFAULT uuid_generate_v4() NOT NULL,
+    name TEXT,
+    position_id INT
+);
+
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS time_limit INT;
+
 CREATE TABLE IF NOT EXISTS videos (
     id SERIAL PRIMARY KEY,
     public_id UUID UNIQUE DEFAULT uuid_generate_v4() NOT NULL,
+    interviews_public_id UUID,
     question_public_id UUID,
     path TEXT
 );
 
+CREATE TABLE IF NOT EXISTS interview_questions (
+    id SERIAL PRIMARY KEY,
+    interview_public_id UUID NOT NULL,
+    question_public_id UUID NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deadline TIMESTAMPTZ NOT NULL,
+    answered_at TIMESTAMPTZ,
+    UNIQUE (interview_public_id, question_public_id),
+    CONSTRAINT fk_interview_questions_interviews FOREIGN KEY (interview_public_id) REFERENCES interviews(public_id) ON DELETE CASCADE
+);
+
 CREATE TABLE IF NOT EXISTS auth (
     id SERIAL PRIMARY KEY,
     user_id INT UNIQUE,
@@ -103,6 +132,226 @@ CREATE TABLE IF NOT EXISTS user_interviews (
     CONSTRAINT fk_user_interviews_interviews FOREIGN KEY (interview_id) REFERENCES interviews(id) ON DELETE CASCADE
 );
 
+CREATE TABLE IF NOT EXISTS transcripts (
+    id SERIAL PRIMARY KEY,
+    interview_public_id UUID NOT NULL,
+    question_public_id UUID NOT NULL,
+    language REGCONFIG NOT NULL DEFAULT 'english',
+    answer TEXT NOT NULL,
+    tsv TSVECTOR NOT NULL,
+    UNIQUE (interview_public_id, question_public_id),
+    CONSTRAINT fk_transcripts_interviews FOREIGN KEY (interview_public_id) REFERENCES interviews(public_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_transcripts_tsv ON transcripts USING GIN (tsv);
+
+CREATE TABLE IF NOT EXISTS question_key_points (
+    id SERIAL PRIMARY KEY,
+    public_id UUID UNIQUE DEFAULT uuid_generate_v4() NOT NULL,
+    question_public_id UUID NOT NULL,
+    text TEXT NOT NULL,
+    keywords JSONB NOT NULL DEFAULT '[]',
+    weight REAL NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_question_key_points_question ON question_key_points (question_public_id);
+
+CREATE TABLE IF NOT EXISTS answer_similarities (
+    id SERIAL PRIMARY KEY,
+    question_public_id UUID NOT NULL,
+    interview_public_id UUID NOT NULL,
+    other_interview_public_id UUID NOT NULL,
+    score REAL NOT NULL,
+    fragments JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (question_public_id, interview_public_id, other_interview_public_id),
+    CONSTRAINT fk_answer_similarities_interviews FOREIGN KEY (interview_public_id) REFERENCES interviews(public_id) ON DELETE CASCADE,
+    CONSTRAINT fk_answer_similarities_other_interviews FOREIGN KEY (other_interview_public_id) REFERENCES interviews(public_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS integrity_events (
+    id SERIAL PRIMARY KEY,
+    interview_public_id UUID NOT NULL,
+    question_public_id UUID,
+    type TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT 'client',
+    occurred_at TIMESTAMPTZ NOT NULL,
+    details JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_integrity_events_interviews FOREIGN KEY (interview_public_id) REFERENCES interviews(public_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_integrity_events_interview ON integrity_events (interview_public_id);
+
+CREATE TABLE IF NOT EXISTS invitations (
+    id SERIAL PRIMARY KEY,
+    public_id UUID UNIQUE DEFAULT uuid_generate_v4() NOT NULL,
+    interview_public_id UUID NOT NULL,
+    nonce TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'sent',
+    expires_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    opened_at TIMESTAMPTZ,
+    started_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    resend_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_invitations_interviews FOREIGN KEY (interview_public_id) REFERENCES interviews(public_id) ON DELETE CASCADE
+);
+
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMPTZ;
+
+-- Interviews answered through POST /question/:id/video before sessions
+-- existed were left pending; they are submitted, or evaluated when scored.
+UPDATE interviews AS i
+SET status = CASE WHEN i.results IS NOT NULL THEN 'evaluated' ELSE 'submitted' END
+WHERE i.status = 'pending'
+    AND (i.results IS NOT NULL OR EXISTS (SELECT 1 FROM videos v WHERE v.interviews_public_id = i.public_id))
+    AND NOT EXISTS (SELECT 1 FROM invitations inv WHERE inv.interview_public_id = i.public_id)
+    AND NOT EXISTS (SELECT 1 FROM interview_questions iq WHERE iq.interview_public_id = i.public_id);
+
+CREATE TABLE IF NOT EXISTS email_deliveries (
+    id SERIAL PRIMARY KEY,
+    interview_public_id UUID,
+    event TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id SERIAL PRIMARY KEY,
+    public_id UUID UNIQUE DEFAULT uuid_generate_v4() NOT NULL,
+    company_public_id UUID NOT NULL,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_webhook_endpoints_companies FOREIGN KEY (company_public_id) REFERENCES companies(public_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    public_id UUID UNIQUE DEFAULT uuid_generate_v4() NOT NULL,
+    endpoint_public_id UUID NOT NULL,
+    event TEXT NOT NULL,
+    interview_public_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_response_code INT,
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    replay_of UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_webhook_deliveries_endpoints FOREIGN KEY (endpoint_public_id) REFERENCES webhook_endpoints(public_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id SERIAL PRIMARY KEY,
+    delivery_public_id UUID NOT NULL,
+    attempt INT NOT NULL,
+    response_code INT,
+    error TEXT,
+    duration_ms BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_webhook_attempts_deliveries FOREIGN KEY (delivery_public_id) REFERENCES webhook_deliveries(public_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_id UUID NOT NULL,
+    event TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    handled TEXT[] NOT NULL DEFAULT '{}',
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (id) WHERE published_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS question_results (
+    id SERIAL PRIMARY KEY,
+    interview_public_id UUID NOT NULL,
+    question_public_id UUID NOT NULL,
+    status TEXT NOT NULL,
+    result JSONB,
+    error TEXT,
+    attempts INT NOT NULL DEFAULT 1,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (interview_public_id, question_public_id),
+    CONSTRAINT fk_question_results_interviews FOREIGN KEY (interview_public_id) REFERENCES interviews(public_id) ON DELETE CASCADE
+);
+
+ALTER TABLE question_results ADD COLUMN IF NOT EXISTS response TEXT;
+
+CREATE TABLE IF NOT EXISTS failed_analyses (
+    id SERIAL PRIMARY KEY,
+    public_id UUID UNIQUE DEFAULT uuid_generate_v4() NOT NULL,
+    interview_public_id UUID UNIQUE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'failed',
+    error TEXT NOT NULL,
+    status_code INT,
+    response_body TEXT,
+    attempts INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_failed_analyses_interviews FOREIGN KEY (interview_public_id) REFERENCES interviews(public_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS failed_analyses_status_idx ON failed_analyses (status, updated_at);
+
+-- Every result an interview had, so that re-analyses and overrides can be traced
+CREATE TABLE IF NOT EXISTS interview_result_versions (
+    id SERIAL PRIMARY KEY,
+    interview_public_id UUID NOT NULL,
+    version INT NOT NULL,
+    source TEXT NOT NULL,
+    score INT NOT NULL,
+    result JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (interview_public_id, version),
+    CONSTRAINT fk_interview_result_versions_interviews FOREIGN KEY (interview_public_id) REFERENCES interviews(public_id) ON DELETE CASCADE
+);
+
+-- Requests to analyze an interview made with an Idempotency-Key header
+CREATE TABLE IF NOT EXISTS analysis_requests (
+    interview_public_id UUID NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'running',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (interview_public_id, idempotency_key),
+    CONSTRAINT fk_analysis_requests_interviews FOREIGN KEY (interview_public_id) REFERENCES interviews(public_id) ON DELETE CASCADE
+);
+
+-- Every status change of an interview is pushed to the progress listeners
+CREATE OR REPLACE FUNCTION notify_interview_status() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('interview_progress', json_build_object(
+        'interview_public_id', NEW.public_id,
+        'type', 'status',
+        'status', NEW.status,
+        'at', NOW()
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS interviews_status_progress ON interviews;
+CREATE TRIGGER interviews_status_progress
+    AFTER UPDATE OF status ON interviews
+    FOR EACH ROW WHEN (OLD.status IS DISTINCT FROM NEW.status)
+    EXECUTE FUNCTION notify_interview_status();
+
 -- Creating references
 ALTER TABLE recruiters ADD CONSTRAINT fk_recruiters_users FOREIGN KEY (public_id) REFERENCES users(public_id) ON DELETE CASCADE;
 ALTER TABLE candidates ADD CONSTRAINT fk_candidates_users FOREIGN KEY (public_id) REFERENCES users(public_id) ON DELETE CASCADE;